@@ -36,6 +36,9 @@ func (cli *DockerCli) CmdInfo(args ...string) error {
 		}
 	}
 	fmt.Fprintf(cli.out, "Execution Driver: %s\n", info.ExecutionDriver)
+	for _, pair := range info.ExecDriverStatus {
+		fmt.Fprintf(cli.out, " %s: %s\n", pair[0], pair[1])
+	}
 	fmt.Fprintf(cli.out, "Logging Driver: %s\n", info.LoggingDriver)
 	fmt.Fprintf(cli.out, "Kernel Version: %s\n", info.KernelVersion)
 	fmt.Fprintf(cli.out, "Operating System: %s\n", info.OperatingSystem)
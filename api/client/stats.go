@@ -24,17 +24,24 @@ type containerStats struct {
 	MemoryPercentage float64
 	NetworkRx        float64
 	NetworkTx        float64
+	BlockRead        float64
+	BlockWrite       float64
+	SizeRw           float64
+	SizeRootFs       float64
 	mu               sync.RWMutex
 	err              error
 }
 
-func (s *containerStats) Collect(cli *DockerCli, streamStats bool) {
+func (s *containerStats) Collect(cli *DockerCli, streamStats, size bool) {
 	v := url.Values{}
 	if streamStats {
 		v.Set("stream", "1")
 	} else {
 		v.Set("stream", "0")
 	}
+	if size {
+		v.Set("size", "1")
+	}
 	stream, _, err := cli.call("GET", "/containers/"+s.Name+"/stats?"+v.Encode(), nil, nil)
 	if err != nil {
 		s.err = err
@@ -70,6 +77,11 @@ func (s *containerStats) Collect(cli *DockerCli, streamStats bool) {
 			s.MemoryPercentage = memPercent
 			s.NetworkRx = float64(v.Network.RxBytes)
 			s.NetworkTx = float64(v.Network.TxBytes)
+			blkRead, blkWrite := calculateBlockIO(v.BlkioStats)
+			s.BlockRead = float64(blkRead)
+			s.BlockWrite = float64(blkWrite)
+			s.SizeRw = float64(v.SizeRw)
+			s.SizeRootFs = float64(v.SizeRootFs)
 			s.mu.Unlock()
 			previousCPU = v.CpuStats.CpuUsage.TotalUsage
 			previousSystem = v.CpuStats.SystemUsage
@@ -103,29 +115,36 @@ func (s *containerStats) Collect(cli *DockerCli, streamStats bool) {
 	}
 }
 
-func (s *containerStats) Display(w io.Writer) error {
+func (s *containerStats) Display(w io.Writer, size bool) error {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	if s.err != nil {
 		return s.err
 	}
-	fmt.Fprintf(w, "%s\t%.2f%%\t%s/%s\t%.2f%%\t%s/%s\n",
+	fmt.Fprintf(w, "%s\t%.2f%%\t%s/%s\t%.2f%%\t%s/%s\t%s/%s",
 		s.Name,
 		s.CPUPercentage,
 		units.HumanSize(s.Memory), units.HumanSize(s.MemoryLimit),
 		s.MemoryPercentage,
-		units.HumanSize(s.NetworkRx), units.HumanSize(s.NetworkTx))
+		units.HumanSize(s.NetworkRx), units.HumanSize(s.NetworkTx),
+		units.HumanSize(s.BlockRead), units.HumanSize(s.BlockWrite))
+	if size {
+		fmt.Fprintf(w, "\t%s (virtual %s)", units.HumanSize(s.SizeRw), units.HumanSize(s.SizeRootFs))
+	}
+	fmt.Fprint(w, "\n")
 	return nil
 }
 
 // CmdStats displays a live stream of resource usage statistics for one or more containers.
 //
-// This shows real-time information on CPU usage, memory usage, and network I/O.
+// This shows real-time information on CPU usage, memory usage, network I/O
+// and block I/O.
 //
 // Usage: docker stats CONTAINER [CONTAINER...]
 func (cli *DockerCli) CmdStats(args ...string) error {
 	cmd := cli.Subcmd("stats", "CONTAINER [CONTAINER...]", "Display a live stream of one or more containers' resource usage statistics", true)
 	noStream := cmd.Bool([]string{"-no-stream"}, false, "Disable streaming stats and only pull the first result")
+	size := cmd.Bool([]string{"s", "-size"}, false, "Display the size of the containers' filesystems")
 	cmd.Require(flag.Min, 1)
 	cmd.ParseFlags(args, true)
 
@@ -140,12 +159,16 @@ func (cli *DockerCli) CmdStats(args ...string) error {
 			fmt.Fprint(cli.out, "\033[2J")
 			fmt.Fprint(cli.out, "\033[H")
 		}
-		io.WriteString(w, "CONTAINER\tCPU %\tMEM USAGE/LIMIT\tMEM %\tNET I/O\n")
+		io.WriteString(w, "CONTAINER\tCPU %\tMEM USAGE/LIMIT\tMEM %\tNET I/O\tBLOCK I/O")
+		if *size {
+			io.WriteString(w, "\tSIZE")
+		}
+		io.WriteString(w, "\n")
 	}
 	for _, n := range names {
 		s := &containerStats{Name: n}
 		cStats = append(cStats, s)
-		go s.Collect(cli, !*noStream)
+		go s.Collect(cli, !*noStream, *size)
 	}
 	// do a quick pause so that any failed connections for containers that do not exist are able to be
 	// evicted before we display the initial or default values.
@@ -165,7 +188,7 @@ func (cli *DockerCli) CmdStats(args ...string) error {
 		printHeader()
 		toRemove := []int{}
 		for i, s := range cStats {
-			if err := s.Display(w); err != nil && !*noStream {
+			if err := s.Display(w, *size); err != nil && !*noStream {
 				toRemove = append(toRemove, i)
 			}
 		}
@@ -198,3 +221,22 @@ func calculateCPUPercent(previousCPU, previousSystem uint64, v *types.Stats) flo
 	}
 	return cpuPercent
 }
+
+// calculateBlockIO returns the bytes read from and written to block devices.
+// The counters of the blkio throttling policy are preferred as they include
+// devices that do not use the CFQ scheduler.
+func calculateBlockIO(blkio types.BlkioStats) (blkRead uint64, blkWrite uint64) {
+	entries := blkio.IoThrottleServiceBytes
+	if len(entries) == 0 {
+		entries = blkio.IoServiceBytesRecursive
+	}
+	for _, bioEntry := range entries {
+		switch strings.ToLower(bioEntry.Op) {
+		case "read":
+			blkRead += bioEntry.Value
+		case "write":
+			blkWrite += bioEntry.Value
+		}
+	}
+	return
+}
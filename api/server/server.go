@@ -23,6 +23,7 @@ import (
 	"github.com/docker/docker/builder"
 	"github.com/docker/docker/cliconfig"
 	"github.com/docker/docker/daemon"
+	"github.com/docker/docker/daemon/execdriver"
 	"github.com/docker/docker/graph"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/jsonmessage"
@@ -200,18 +201,23 @@ func httpError(w http.ResponseWriter, err error) {
 	// If we need to differentiate between different possible error types, we should
 	// create appropriate error types with clearly defined meaning.
 	errStr := strings.ToLower(err.Error())
-	for keyword, status := range map[string]int{
-		"not found":             http.StatusNotFound,
-		"no such":               http.StatusNotFound,
-		"bad parameter":         http.StatusBadRequest,
-		"conflict":              http.StatusConflict,
-		"impossible":            http.StatusNotAcceptable,
-		"wrong login/password":  http.StatusUnauthorized,
-		"hasn't been activated": http.StatusForbidden,
-	} {
-		if strings.Contains(errStr, keyword) {
-			statusCode = status
-			break
+	if execdriver.IsContainerStateError(err) {
+		// the container is not in a state allowing the operation
+		statusCode = http.StatusConflict
+	} else {
+		for keyword, status := range map[string]int{
+			"not found":             http.StatusNotFound,
+			"no such":               http.StatusNotFound,
+			"bad parameter":         http.StatusBadRequest,
+			"conflict":              http.StatusConflict,
+			"impossible":            http.StatusNotAcceptable,
+			"wrong login/password":  http.StatusUnauthorized,
+			"hasn't been activated": http.StatusForbidden,
+		} {
+			if strings.Contains(errStr, keyword) {
+				statusCode = status
+				break
+			}
 		}
 	}
 
@@ -338,6 +344,53 @@ func (s *Server) getContainersExport(version version.Version, w http.ResponseWri
 	return s.daemon.ContainerExport(vars["name"], w)
 }
 
+func (s *Server) getContainersDiagnostics(version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+
+	// by default include the last 64KB of output
+	tail := int64(64 * 1024)
+	if r.Form.Get("tail") != "" {
+		t, err := strconv.ParseInt(r.Form.Get("tail"), 10, 64)
+		if err != nil {
+			return err
+		}
+		tail = t
+	}
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	return s.daemon.ContainerDiagnostics(vars["name"], tail, w)
+}
+
+func (s *Server) postContainersPerf(version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+
+	subcommand := r.Form.Get("subcommand")
+	if subcommand == "" {
+		subcommand = "stat"
+	}
+	duration := 10 * time.Second
+	if r.Form.Get("duration") != "" {
+		d, err := strconv.Atoi(r.Form.Get("duration"))
+		if err != nil {
+			return err
+		}
+		duration = time.Duration(d) * time.Second
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	return s.daemon.ContainerPerf(vars["name"], subcommand, duration, w)
+}
+
 func (s *Server) getImagesJSON(version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := parseForm(r); err != nil {
 		return err
@@ -554,7 +607,7 @@ func (s *Server) getContainersStats(version version.Version, w http.ResponseWrit
 		return fmt.Errorf("Missing parameter")
 	}
 
-	return s.daemon.ContainerStats(vars["name"], boolValue(r, "stream"), ioutils.NewWriteFlusher(w))
+	return s.daemon.ContainerStats(vars["name"], boolValue(r, "stream"), boolValue(r, "size"), ioutils.NewWriteFlusher(w))
 }
 
 func (s *Server) getContainersLogs(version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
@@ -908,6 +961,88 @@ func (s *Server) postContainersRestart(version version.Version, w http.ResponseW
 	return nil
 }
 
+func (s *Server) postExecDriverReload(version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := checkForJson(r); err != nil {
+		return err
+	}
+
+	var config struct {
+		ExecOptions []string
+	}
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		return err
+	}
+
+	if err := s.daemon.ReloadExecDriver(config.ExecOptions); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) postContainerUpdate(version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	if err := checkForJson(r); err != nil {
+		return err
+	}
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+
+	hostConfig, err := runconfig.DecodeHostConfig(r.Body)
+	if err != nil {
+		return err
+	}
+
+	warnings, err := s.daemon.ContainerUpdate(vars["name"], hostConfig)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, &types.ContainerUpdateResponse{
+		Warnings: warnings,
+	})
+}
+
+func (s *Server) postContainerDevices(version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	if err := checkForJson(r); err != nil {
+		return err
+	}
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+
+	var mapping runconfig.DeviceMapping
+	if err := json.NewDecoder(r.Body).Decode(&mapping); err != nil {
+		return err
+	}
+	if mapping.PathOnHost == "" {
+		return fmt.Errorf("Missing PathOnHost")
+	}
+
+	if err := s.daemon.ContainerAddDevice(vars["name"], mapping); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) postContainerStatsReset(version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+
+	if err := s.daemon.ContainerStatsReset(vars["name"]); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
 func (s *Server) postContainerRename(version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := parseForm(r); err != nil {
 		return err
@@ -1387,6 +1522,33 @@ func (s *Server) postContainerExecResize(version version.Version, w http.Respons
 	return s.daemon.ContainerExecResize(vars["name"], height, width)
 }
 
+func (s *Server) postContainerExecKill(version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+
+	var sig uint64
+	if sigStr := r.Form.Get("signal"); sigStr != "" {
+		// the signal is either a number or a name like "KILL" or "SIGKILL"
+		var err error
+		if sig, err = strconv.ParseUint(sigStr, 10, 5); err != nil {
+			sig = uint64(signal.SignalMap[strings.TrimPrefix(sigStr, "SIG")])
+		}
+		if sig == 0 {
+			return fmt.Errorf("Invalid signal: %s", sigStr)
+		}
+	}
+
+	if err := s.daemon.ContainerExecKill(vars["name"], sig); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
 func (s *Server) optionsHandler(version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	w.WriteHeader(http.StatusOK)
 	return nil
@@ -1398,6 +1560,11 @@ func writeCorsHeaders(w http.ResponseWriter, r *http.Request, corsHeaders string
 	w.Header().Add("Access-Control-Allow-Methods", "GET, POST, DELETE, PUT, OPTIONS")
 }
 
+func (s *Server) getMetrics(version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	return s.daemon.ExecDriverMetrics(w)
+}
+
 func (s *Server) ping(version version.Version, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	_, err := w.Write([]byte{'O', 'K'})
 	return err
@@ -1466,50 +1633,58 @@ func createRouter(s *Server) *mux.Router {
 	}
 	m := map[string]map[string]HttpApiFunc{
 		"GET": {
-			"/_ping":                          s.ping,
-			"/events":                         s.getEvents,
-			"/info":                           s.getInfo,
-			"/version":                        s.getVersion,
-			"/images/json":                    s.getImagesJSON,
-			"/images/search":                  s.getImagesSearch,
-			"/images/get":                     s.getImagesGet,
-			"/images/{name:.*}/get":           s.getImagesGet,
-			"/images/{name:.*}/history":       s.getImagesHistory,
-			"/images/{name:.*}/json":          s.getImagesByName,
-			"/containers/ps":                  s.getContainersJSON,
-			"/containers/json":                s.getContainersJSON,
-			"/containers/{name:.*}/export":    s.getContainersExport,
-			"/containers/{name:.*}/changes":   s.getContainersChanges,
-			"/containers/{name:.*}/json":      s.getContainersByName,
-			"/containers/{name:.*}/top":       s.getContainersTop,
-			"/containers/{name:.*}/logs":      s.getContainersLogs,
-			"/containers/{name:.*}/stats":     s.getContainersStats,
-			"/containers/{name:.*}/attach/ws": s.wsContainersAttach,
-			"/exec/{id:.*}/json":              s.getExecByID,
+			"/_ping":                            s.ping,
+			"/events":                           s.getEvents,
+			"/info":                             s.getInfo,
+			"/metrics":                          s.getMetrics,
+			"/version":                          s.getVersion,
+			"/images/json":                      s.getImagesJSON,
+			"/images/search":                    s.getImagesSearch,
+			"/images/get":                       s.getImagesGet,
+			"/images/{name:.*}/get":             s.getImagesGet,
+			"/images/{name:.*}/history":         s.getImagesHistory,
+			"/images/{name:.*}/json":            s.getImagesByName,
+			"/containers/ps":                    s.getContainersJSON,
+			"/containers/json":                  s.getContainersJSON,
+			"/containers/{name:.*}/export":      s.getContainersExport,
+			"/containers/{name:.*}/diagnostics": s.getContainersDiagnostics,
+			"/containers/{name:.*}/changes":     s.getContainersChanges,
+			"/containers/{name:.*}/json":        s.getContainersByName,
+			"/containers/{name:.*}/top":         s.getContainersTop,
+			"/containers/{name:.*}/logs":        s.getContainersLogs,
+			"/containers/{name:.*}/stats":       s.getContainersStats,
+			"/containers/{name:.*}/attach/ws":   s.wsContainersAttach,
+			"/exec/{id:.*}/json":                s.getExecByID,
 		},
 		"POST": {
-			"/auth":                         s.postAuth,
-			"/commit":                       s.postCommit,
-			"/build":                        s.postBuild,
-			"/images/create":                s.postImagesCreate,
-			"/images/load":                  s.postImagesLoad,
-			"/images/{name:.*}/push":        s.postImagesPush,
-			"/images/{name:.*}/tag":         s.postImagesTag,
-			"/containers/create":            s.postContainersCreate,
-			"/containers/{name:.*}/kill":    s.postContainersKill,
-			"/containers/{name:.*}/pause":   s.postContainersPause,
-			"/containers/{name:.*}/unpause": s.postContainersUnpause,
-			"/containers/{name:.*}/restart": s.postContainersRestart,
-			"/containers/{name:.*}/start":   s.postContainersStart,
-			"/containers/{name:.*}/stop":    s.postContainersStop,
-			"/containers/{name:.*}/wait":    s.postContainersWait,
-			"/containers/{name:.*}/resize":  s.postContainersResize,
-			"/containers/{name:.*}/attach":  s.postContainersAttach,
-			"/containers/{name:.*}/copy":    s.postContainersCopy,
-			"/containers/{name:.*}/exec":    s.postContainerExecCreate,
-			"/exec/{name:.*}/start":         s.postContainerExecStart,
-			"/exec/{name:.*}/resize":        s.postContainerExecResize,
-			"/containers/{name:.*}/rename":  s.postContainerRename,
+			"/auth":                             s.postAuth,
+			"/commit":                           s.postCommit,
+			"/build":                            s.postBuild,
+			"/images/create":                    s.postImagesCreate,
+			"/images/load":                      s.postImagesLoad,
+			"/images/{name:.*}/push":            s.postImagesPush,
+			"/images/{name:.*}/tag":             s.postImagesTag,
+			"/containers/create":                s.postContainersCreate,
+			"/containers/{name:.*}/kill":        s.postContainersKill,
+			"/containers/{name:.*}/pause":       s.postContainersPause,
+			"/containers/{name:.*}/unpause":     s.postContainersUnpause,
+			"/containers/{name:.*}/restart":     s.postContainersRestart,
+			"/containers/{name:.*}/start":       s.postContainersStart,
+			"/containers/{name:.*}/stop":        s.postContainersStop,
+			"/containers/{name:.*}/wait":        s.postContainersWait,
+			"/containers/{name:.*}/resize":      s.postContainersResize,
+			"/containers/{name:.*}/attach":      s.postContainersAttach,
+			"/containers/{name:.*}/copy":        s.postContainersCopy,
+			"/containers/{name:.*}/exec":        s.postContainerExecCreate,
+			"/exec/{name:.*}/start":             s.postContainerExecStart,
+			"/exec/{name:.*}/resize":            s.postContainerExecResize,
+			"/exec/{name:.*}/kill":              s.postContainerExecKill,
+			"/containers/{name:.*}/rename":      s.postContainerRename,
+			"/containers/{name:.*}/update":      s.postContainerUpdate,
+			"/containers/{name:.*}/devices":     s.postContainerDevices,
+			"/containers/{name:.*}/stats/reset": s.postContainerStatsReset,
+			"/containers/{name:.*}/perf":        s.postContainersPerf,
+			"/execdriver/reload":                s.postExecDriverReload,
 		},
 		"DELETE": {
 			"/containers/{name:.*}": s.deleteContainers,
@@ -46,6 +46,10 @@ type MemoryStats struct {
 	// number of times memory usage hits limits.
 	Failcnt uint64 `json:"failcnt"`
 	Limit   uint64 `json:"limit"`
+	// whether the OOM killer is disabled for the container.
+	OomKillDisable bool `json:"oom_kill_disable"`
+	// OOM score adjustment of the container's init process.
+	OomScoreAdj int `json:"oom_score_adj"`
 }
 
 type BlkioStatEntry struct {
@@ -65,6 +69,8 @@ type BlkioStats struct {
 	IoMergedRecursive       []BlkioStatEntry `json:"io_merged_recursive"`
 	IoTimeRecursive         []BlkioStatEntry `json:"io_time_recursive"`
 	SectorsRecursive        []BlkioStatEntry `json:"sectors_recursive"`
+	IoThrottleServiceBytes  []BlkioStatEntry `json:"io_throttle_service_bytes"`
+	IoThrottleServiced      []BlkioStatEntry `json:"io_throttle_serviced"`
 }
 
 type Network struct {
@@ -84,4 +90,16 @@ type Stats struct {
 	CpuStats    CpuStats    `json:"cpu_stats,omitempty"`
 	MemoryStats MemoryStats `json:"memory_stats,omitempty"`
 	BlkioStats  BlkioStats  `json:"blkio_stats,omitempty"`
+	ExecStats   *ExecStats  `json:"exec_stats,omitempty"`
+	// size of the container's filesystem, only when requested
+	SizeRw     int64 `json:"size_rw,omitempty"`
+	SizeRootFs int64 `json:"size_root_fs,omitempty"`
+}
+
+// ExecStats is the usage of the processes exec'd in the container with
+// resources of their own, which is included in the container's usage.
+type ExecStats struct {
+	Sessions    int    `json:"sessions"`
+	CpuUsage    uint64 `json:"cpu_usage"`
+	MemoryUsage uint64 `json:"memory_usage"`
 }
@@ -152,6 +152,7 @@ type Info struct {
 	NGoroutines        int
 	SystemTime         string
 	ExecutionDriver    string
+	ExecDriverStatus   [][2]string
 	LoggingDriver      string
 	NEventsListener    int
 	KernelVersion      string
@@ -181,16 +182,35 @@ type ExecStartCheck struct {
 }
 
 type ContainerState struct {
-	Running    bool
-	Paused     bool
-	Restarting bool
-	OOMKilled  bool
-	Dead       bool
-	Pid        int
-	ExitCode   int
-	Error      string
-	StartedAt  time.Time
-	FinishedAt time.Time
+	Running       bool
+	Paused        bool
+	Restarting    bool
+	OOMKilled     bool
+	OOMReport     *OOMReport `json:",omitempty"`
+	Dead          bool
+	Pid           int
+	ExitCode      int
+	ExitSignal    string `json:",omitempty"`
+	ExitInitiator string `json:",omitempty"`
+	Error         string
+	StartedAt     time.Time
+	FinishedAt    time.Time
+}
+
+// OOMReport explains which process the kernel killed when the container ran
+// out of memory.
+type OOMReport struct {
+	VictimPid  int
+	VictimComm string
+	Processes  []OOMProcess
+	Message    string
+}
+
+// OOMProcess is a process of the container at the time of the OOM kill.
+type OOMProcess struct {
+	Pid  int
+	Comm string
+	RSS  uint64 // resident set size in pages
 }
 
 // GET "/containers/{name:.*}/json"
@@ -219,3 +239,9 @@ type ContainerJSON struct {
 	ExecIDs         []string
 	HostConfig      *runconfig.HostConfig
 }
+
+// POST /containers/{name:.*}/update
+type ContainerUpdateResponse struct {
+	// Warnings are any warnings encountered during the update of the container.
+	Warnings []string
+}
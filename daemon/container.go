@@ -237,6 +237,7 @@ func (container *Container) Start() (err error) {
 	if container.removalInProgress || container.Dead {
 		return fmt.Errorf("Container is marked for removal and cannot be started.")
 	}
+	container.HasBeenManuallyStopped = false
 
 	// if we encounter an error during start we need to ensure that any other
 	// setup has been cleaned up properly
@@ -360,27 +361,40 @@ func (container *Container) KillSig(sig int) error {
 	container.Lock()
 	defer container.Unlock()
 
-	// We could unpause the container for them rather than returning this error
+	// drivers that cannot signal paused containers need them unpaused first
 	if container.Paused {
-		return fmt.Errorf("Container %s is paused. Unpause the container before stopping", container.ID)
+		canKill, err := container.canKillPaused()
+		if err != nil {
+			return err
+		}
+		if !canKill {
+			return fmt.Errorf("Container %s is paused. Unpause the container before stopping", container.ID)
+		}
 	}
 
 	if !container.Running {
 		return nil
 	}
 
-	// signal to the monitor that it should not restart the container
-	// after we send the kill signal
-	container.monitor.ExitOnNext()
-
-	// if the container is currently restarting we do not need to send the signal
-	// to the process.  Telling the monitor that it should exit on it's next event
-	// loop is enough
-	if container.Restarting {
-		return nil
+	// the supervisor does not restart the container after we send the kill
+	// signal, and does not send it to a restarting container: stopping the
+	// restart is enough
+	if err := container.monitor.supervisor.Kill(sig); err != nil {
+		return err
 	}
+	// the driver resumed the container to deliver the signal
+	container.Paused = false
+	return nil
+}
 
-	return container.daemon.Kill(container, sig)
+// canKillPaused returns true if the exec driver resumes paused containers to
+// signal them.
+func (container *Container) canKillPaused() (bool, error) {
+	ed, err := container.execDriver()
+	if err != nil {
+		return false, err
+	}
+	return execdriver.GetDriverCapabilities(ed).Has(execdriver.FeatureKillPaused), nil
 }
 
 // Wrapper aroung KillSig() suppressing "no such process" error.
@@ -407,7 +421,14 @@ func (container *Container) Pause() error {
 		return fmt.Errorf("Container %s is not running", container.ID)
 	}
 
-	if err := container.daemon.execDriver.Pause(container.command); err != nil {
+	ed, err := container.execDriver()
+	if err != nil {
+		return err
+	}
+	if err := execdriver.CheckFeature(ed, execdriver.FeaturePause); err != nil {
+		return err
+	}
+	if err := ed.Pause(container.command); err != nil {
 		return err
 	}
 	container.Paused = true
@@ -428,7 +449,11 @@ func (container *Container) Unpause() error {
 		return fmt.Errorf("Container %s is not running", container.ID)
 	}
 
-	if err := container.daemon.execDriver.Unpause(container.command); err != nil {
+	ed, err := container.execDriver()
+	if err != nil {
+		return err
+	}
+	if err := ed.Unpause(container.command); err != nil {
 		return err
 	}
 	container.Paused = false
@@ -459,6 +484,14 @@ func (container *Container) Stop(seconds int) error {
 		return nil
 	}
 
+	ed, err := container.execDriver()
+	if err != nil {
+		return err
+	}
+	if _, ok := ed.(execdriver.Stopper); ok {
+		return container.stopWithDriver(seconds)
+	}
+
 	// 1. Send a SIGTERM
 	if err := container.killPossiblyDeadProcess(15); err != nil {
 		logrus.Infof("Failed to send SIGTERM to the process, force killing")
@@ -479,6 +512,36 @@ func (container *Container) Stop(seconds int) error {
 	return nil
 }
 
+// stopWithDriver lets the exec driver send SIGTERM and escalate to SIGKILL
+// after the timeout.
+func (container *Container) stopWithDriver(seconds int) error {
+	container.Lock()
+	if container.Paused {
+		canKill, err := container.canKillPaused()
+		if err != nil {
+			container.Unlock()
+			return err
+		}
+		if !canKill {
+			container.Unlock()
+			return fmt.Errorf("Container %s is paused. Unpause the container before stopping", container.ID)
+		}
+	}
+	if !container.Running {
+		container.Unlock()
+		return nil
+	}
+	supervisor := container.monitor.supervisor
+	container.Unlock()
+
+	// the supervisor does not restart the container once it is stopped
+	if err := supervisor.Stop(time.Duration(seconds)*time.Second, syscall.SIGTERM); err != nil {
+		return err
+	}
+	container.WaitStop(-1 * time.Second)
+	return nil
+}
+
 func (container *Container) Restart(seconds int) error {
 	// Avoid unnecessarily unmounting and then directly mounting
 	// the container when the container stops and then starts
@@ -494,6 +557,14 @@ func (container *Container) Restart(seconds int) error {
 }
 
 func (container *Container) Resize(h, w int) error {
+	ed, err := container.execDriver()
+	if err != nil {
+		return err
+	}
+	if r, ok := ed.(execdriver.Resizer); ok && container.Config.Tty {
+		// the driver applies the size once the console is created
+		return r.Resize(container.ID, h, w)
+	}
 	if !container.IsRunning() {
 		return fmt.Errorf("Cannot resize container %s, container is not running", container.ID)
 	}
@@ -696,7 +767,11 @@ func (container *Container) startLogging() error {
 }
 
 func (container *Container) waitForStart() error {
-	container.monitor = newContainerMonitor(container, container.hostConfig.RestartPolicy)
+	monitor, err := newContainerMonitor(container, container.hostConfig.RestartPolicy)
+	if err != nil {
+		return err
+	}
+	container.monitor = monitor
 
 	// block until we either receive an error from the initial start of the container's
 	// process or until the process is running in the container
@@ -749,6 +824,7 @@ func (container *Container) Exec(execConfig *execConfig) error {
 	waitStart := make(chan struct{})
 
 	callback := func(processConfig *execdriver.ProcessConfig, pid int) {
+		execConfig.Pid = pid
 		if processConfig.Tty {
 			// The callback is called after the process Start()
 			// so we are in the parent process. In TTY mode, stdin/out/err is the PtySlave
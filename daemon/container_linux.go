@@ -43,6 +43,10 @@ type Container struct {
 
 	AppArmorProfile string
 
+	// UnconfinedSystemPaths leaves the paths of /proc that are masked or
+	// read only by default as they are.
+	UnconfinedSystemPaths bool
+
 	// Store rw/ro in a separate structure to preserve reverse-compatibility on-disk.
 	// Easier than migrating older container configs :)
 	VolumesRW map[string]bool
@@ -149,6 +153,27 @@ func (container *Container) createDaemonEnvironment(linkedEnv []string) []string
 	return env
 }
 
+// getThrottleDevices resolves the block devices of the limits to their
+// device numbers.
+func getThrottleDevices(limits []runconfig.ThrottleDevice) ([]*execdriver.ThrottleDevice, error) {
+	var out []*execdriver.ThrottleDevice
+	for _, l := range limits {
+		path, err := filepath.EvalSymlinks(l.Path)
+		if err != nil {
+			return nil, err
+		}
+		device, err := devices.DeviceFromPath(path, "")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", l.Path, err)
+		}
+		if device.Type != 'b' {
+			return nil, fmt.Errorf("%s is not a block device", l.Path)
+		}
+		out = append(out, &execdriver.ThrottleDevice{Major: device.Major, Minor: device.Minor, Rate: l.Rate})
+	}
+	return out, nil
+}
+
 func getDevicesFromPath(deviceMapping runconfig.DeviceMapping) (devs []*configs.Device, err error) {
 	device, err := devices.DeviceFromPath(deviceMapping.PathOnHost, deviceMapping.CgroupPermissions)
 	// if there was no error, return the device
@@ -202,7 +227,7 @@ func populateCommand(c *Container, env []string) error {
 		en.ContainerID = nc.ID
 	}
 
-	ipc := &execdriver.Ipc{}
+	ipc := &execdriver.Ipc{ShmSize: c.hostConfig.ShmSize}
 
 	if c.hostConfig.IpcMode.IsContainer() {
 		ic, err := c.getIpcContainer()
@@ -215,10 +240,20 @@ func populateCommand(c *Container, env []string) error {
 	}
 
 	pid := &execdriver.Pid{}
-	pid.HostPid = c.hostConfig.PidMode.IsHost()
+
+	if c.hostConfig.PidMode.IsContainer() {
+		pc, err := c.getPidContainer()
+		if err != nil {
+			return err
+		}
+		pid.ContainerID = pc.ID
+	} else {
+		pid.HostPid = c.hostConfig.PidMode.IsHost()
+	}
 
 	uts := &execdriver.UTS{
-		HostUTS: c.hostConfig.UTSMode.IsHost(),
+		HostUTS:    c.hostConfig.UTSMode.IsHost(),
+		Domainname: c.Config.Domainname,
 	}
 
 	// Build lists of devices allowed and created within the container.
@@ -274,6 +309,29 @@ func populateCommand(c *Container, env []string) error {
 		BlkioWeight:    c.hostConfig.BlkioWeight,
 		Rlimits:        rlimits,
 		OomKillDisable: c.hostConfig.OomKillDisable,
+		CpuRtRuntime:   c.hostConfig.CpuRtRuntime,
+		CpuRtPeriod:    c.hostConfig.CpuRtPeriod,
+		MemoryHigh:     c.hostConfig.MemoryHigh,
+	}
+	for _, t := range []struct {
+		limits []runconfig.ThrottleDevice
+		out    *[]*execdriver.ThrottleDevice
+	}{
+		{c.hostConfig.BlkioReadBps, &resources.BlkioReadBps},
+		{c.hostConfig.BlkioWriteBps, &resources.BlkioWriteBps},
+		{c.hostConfig.BlkioReadIOps, &resources.BlkioReadIOps},
+		{c.hostConfig.BlkioWriteIOps, &resources.BlkioWriteIOps},
+	} {
+		devs, err := getThrottleDevices(t.limits)
+		if err != nil {
+			return err
+		}
+		*t.out = devs
+	}
+
+	var tmpfs []execdriver.TmpfsMount
+	for _, t := range c.hostConfig.Tmpfs {
+		tmpfs = append(tmpfs, execdriver.TmpfsMount{Destination: t.Path, Size: t.Size, Mode: t.Mode})
 	}
 
 	processConfig := execdriver.ProcessConfig{
@@ -308,6 +366,18 @@ func populateCommand(c *Container, env []string) error {
 		LxcConfig:          lxcConfig,
 		AppArmorProfile:    c.AppArmorProfile,
 		CgroupParent:       c.hostConfig.CgroupParent,
+		DebugStart:         c.hostConfig.DebugStart,
+		RtPolicy:           c.hostConfig.CpuRtPolicy,
+		RtPriority:         c.hostConfig.CpuRtPriority,
+		OomScoreAdj:        c.hostConfig.OomScoreAdj,
+		NetClassID:         c.hostConfig.NetClassID,
+		NetPrioMap:         c.hostConfig.NetPrioMap,
+		Tmpfs:              tmpfs,
+		Init:               c.hostConfig.Init,
+	}
+	if c.UnconfinedSystemPaths {
+		c.command.MaskPaths = []string{}
+		c.command.ReadonlyPaths = []string{}
 	}
 
 	return nil
@@ -795,7 +865,7 @@ func (container *Container) initializeNetworking() error {
 		container.Config.NetworkDisabled = true
 	}
 
-	if container.hostConfig.NetworkMode.IsHost() {
+	if container.hostConfig.NetworkMode.IsHost() || container.hostConfig.UTSMode.IsHost() {
 		container.Config.Hostname, err = os.Hostname()
 		if err != nil {
 			return err
@@ -860,6 +930,21 @@ func (container *Container) getIpcContainer() (*Container, error) {
 	if !c.IsRunning() {
 		return nil, fmt.Errorf("cannot join IPC of a non running container: %s", containerID)
 	}
+	if !c.hostConfig.IpcMode.IsShareable() {
+		return nil, fmt.Errorf("cannot join the private IPC of container: %s", containerID)
+	}
+	return c, nil
+}
+
+func (container *Container) getPidContainer() (*Container, error) {
+	containerID := container.hostConfig.PidMode.Container()
+	c, err := container.daemon.Get(containerID)
+	if err != nil {
+		return nil, err
+	}
+	if !c.IsRunning() {
+		return nil, fmt.Errorf("cannot join PID of a non running container: %s", containerID)
+	}
 	return c, nil
 }
 
@@ -8,6 +8,8 @@ import (
 
 	"github.com/docker/docker/daemon/execdriver"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/runconfig"
+	"github.com/docker/libcontainer/configs"
 )
 
 // TODO Windows. A reasonable default at the moment.
@@ -28,6 +30,10 @@ type Container struct {
 
 	AppArmorProfile string
 
+	// UnconfinedSystemPaths leaves the paths of /proc that are masked or
+	// read only by default as they are.
+	UnconfinedSystemPaths bool
+
 	// Store rw/ro in a separate structure to preserve reverse-compatibility on-disk.
 	// Easier than migrating older container configs :)
 	VolumesRW map[string]bool
@@ -171,3 +177,7 @@ func disableAllActiveLinks(container *Container) {
 
 func (container *Container) DisableLink(name string) {
 }
+
+func getDevicesFromPath(deviceMapping runconfig.DeviceMapping) ([]*configs.Device, error) {
+	return nil, fmt.Errorf("Windows: Devices are not supported")
+}
@@ -24,6 +24,12 @@ func (daemon *Daemon) ContainerCreate(name string, config *runconfig.Config, hos
 		return "", warnings, fmt.Errorf("The working directory '%s' is invalid. It needs to be an absolute path.", config.WorkingDir)
 	}
 
+	// a container in the host's UTS namespace would change the hostname of
+	// the host
+	if hostConfig != nil && hostConfig.UTSMode.IsHost() && (config.Hostname != "" || config.Domainname != "") {
+		return "", warnings, fmt.Errorf("Conflicting options: the hostname and the UTS mode of the container, which uses the host's.")
+	}
+
 	container, buildWarnings, err := daemon.Create(config, hostConfig, name)
 	if err != nil {
 		if daemon.Graph().IsNotExist(err, config.Image) {
@@ -120,5 +126,16 @@ func (daemon *Daemon) GenerateSecurityOpt(ipcMode runconfig.IpcMode, pidMode run
 
 		return label.DupSecOpt(c.ProcessLabel), nil
 	}
+	if pidContainer := pidMode.Container(); pidContainer != "" {
+		c, err := daemon.Get(pidContainer)
+		if err != nil {
+			return nil, err
+		}
+		if !c.IsRunning() {
+			return nil, fmt.Errorf("cannot join PID of a non running container: %s", pidContainer)
+		}
+
+		return label.DupSecOpt(c.ProcessLabel), nil
+	}
 	return nil, nil
 }
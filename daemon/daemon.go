@@ -104,6 +104,8 @@ type Daemon struct {
 	containerGraph   *graphdb.Database
 	driver           graphdriver.Driver
 	execDriver       execdriver.Driver
+	execDrivers      map[string]execdriver.Driver // requested by containers
+	execDriversLock  sync.Mutex
 	statsCollector   *statsCollector
 	defaultLogConfig runconfig.LogConfig
 	RegistryService  *registry.Service
@@ -211,6 +213,10 @@ func (daemon *Daemon) register(container *Container, updateSuffixarray bool) err
 
 	container.registerVolumes()
 
+	if container.IsRunning() && daemon.reattach(container) {
+		return nil
+	}
+
 	if container.IsRunning() {
 		logrus.Debugf("killing old running container %s", container.ID)
 
@@ -220,7 +226,11 @@ func (daemon *Daemon) register(container *Container, updateSuffixarray bool) err
 		cmd := &execdriver.Command{
 			ID: container.ID,
 		}
-		daemon.execDriver.Terminate(cmd)
+		if ed, err := container.execDriver(); err != nil {
+			logrus.Errorf("Cannot kill old running container %s: %v", container.ID, err)
+		} else {
+			ed.Terminate(cmd)
+		}
 
 		if err := container.Unmount(); err != nil {
 			logrus.Debugf("unmount error %s", err)
@@ -233,6 +243,47 @@ func (daemon *Daemon) register(container *Container, updateSuffixarray bool) err
 	return nil
 }
 
+// reattach monitors a container left running by a previous instance of the
+// daemon if the driver restored it.  Containers with networking allocated by
+// the daemon are not reattached because their endpoints cannot be restored.
+func (daemon *Daemon) reattach(container *Container) bool {
+	ed, err := container.execDriver()
+	if err != nil {
+		logrus.Errorf("Cannot reattach to container %s: %v", container.ID, err)
+		return false
+	}
+	r, ok := ed.(execdriver.Reattacher)
+	if !ok || !r.Restored(container.ID) {
+		return false
+	}
+	if container.isNetworkAllocated() {
+		logrus.Infof("Cannot reattach to container %s, its network cannot be restored", container.ID)
+		return false
+	}
+	if err := container.Mount(); err != nil {
+		logrus.Errorf("Cannot reattach to container %s: %v", container.ID, err)
+		return false
+	}
+	logrus.Debugf("reattaching to running container %s", container.ID)
+	container.command = &execdriver.Command{
+		ID: container.ID,
+		// the limits the driver keeps enforcing while the container runs
+		Resources: &execdriver.Resources{
+			CpuQuota:   container.hostConfig.CpuQuota,
+			MemoryHigh: container.hostConfig.MemoryHigh,
+		},
+	}
+	monitor, err := newContainerMonitor(container, container.hostConfig.RestartPolicy)
+	if err != nil {
+		logrus.Errorf("Cannot reattach to container %s: %v", container.ID, err)
+		container.Unmount()
+		return false
+	}
+	container.monitor = monitor
+	go container.monitor.Reattach()
+	return true
+}
+
 func (daemon *Daemon) ensureName(container *Container) error {
 	if container.Name == "" {
 		name, err := daemon.generateNewName(container.ID)
@@ -323,13 +374,15 @@ func (daemon *Daemon) restore() error {
 	}
 
 	// check the restart policy on the containers and restart any container with
-	// the restart policy of "always"
+	// the restart policy of "always", or "unless-stopped" unless the user stopped it
 	if daemon.config.AutoRestart {
 		logrus.Debug("Restarting containers...")
 
 		for _, container := range registeredContainers {
-			if container.hostConfig.RestartPolicy.IsAlways() ||
-				(container.hostConfig.RestartPolicy.IsOnFailure() && container.ExitCode != 0) {
+			policy := container.hostConfig.RestartPolicy
+			if policy.IsAlways() ||
+				(policy.IsUnlessStopped() && !container.HasBeenManuallyStopped) ||
+				(policy.IsOnFailure() && container.ExitCode != 0) {
 				logrus.Debugf("Starting container %s", container.ID)
 
 				if err := container.Start(); err != nil {
@@ -490,6 +543,9 @@ func parseSecurityOpt(container *Container, config *runconfig.HostConfig) error
 
 	for _, opt := range config.SecurityOpt {
 		con := strings.SplitN(opt, ":", 2)
+		if len(con) == 1 {
+			con = strings.SplitN(opt, "=", 2)
+		}
 		if len(con) == 1 {
 			return fmt.Errorf("Invalid --security-opt: %q", opt)
 		}
@@ -498,6 +554,11 @@ func parseSecurityOpt(container *Container, config *runconfig.HostConfig) error
 			labelOpts = append(labelOpts, con[1])
 		case "apparmor":
 			container.AppArmorProfile = con[1]
+		case "systempaths":
+			if con[1] != "unconfined" {
+				return fmt.Errorf("Invalid --security-opt: %q", opt)
+			}
+			container.UnconfinedSystemPaths = true
 		default:
 			return fmt.Errorf("Invalid --security-opt: %q", opt)
 		}
@@ -863,6 +924,11 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 	if err != nil {
 		return nil, err
 	}
+	caps := execdriver.GetDriverCapabilities(ed)
+	if caps.Version > execdriver.APIVersion {
+		logrus.Warnf("Exec driver %s implements interface version %d, newer than the daemon's %d", ed.Name(), caps.Version, execdriver.APIVersion)
+	}
+	logrus.Debugf("Exec driver %s version %d supports %v", ed.Name(), caps.Version, caps.Features)
 
 	d.ID = trustKey.PublicKey().KeyID()
 	d.repository = daemonRepo
@@ -876,7 +942,8 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 	d.config = config
 	d.sysInitPath = sysInitPath
 	d.execDriver = ed
-	d.statsCollector = newStatsCollector(1 * time.Second)
+	d.execDrivers = make(map[string]execdriver.Driver)
+	d.statsCollector = newStatsCollector(1*time.Second, ed)
 	d.defaultLogConfig = config.LogConfig
 	d.RegistryService = registryService
 	d.EventsService = eventsService
@@ -1010,6 +1077,9 @@ func (daemon *Daemon) Shutdown() error {
 		}
 		group.Wait()
 	}
+	if daemon.execDriver != nil {
+		daemon.shutdownExecDrivers()
+	}
 
 	return nil
 }
@@ -1045,15 +1115,27 @@ func (daemon *Daemon) Diff(container *Container) (archive.Archive, error) {
 }
 
 func (daemon *Daemon) Run(c *Container, pipes *execdriver.Pipes, startCallback execdriver.StartCallback) (execdriver.ExitStatus, error) {
-	return daemon.execDriver.Run(c.command, pipes, startCallback)
+	ed, err := c.execDriver()
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	return ed.Run(c.command, pipes, startCallback)
 }
 
 func (daemon *Daemon) Kill(c *Container, sig int) error {
-	return daemon.execDriver.Kill(c.command, sig)
+	ed, err := c.execDriver()
+	if err != nil {
+		return err
+	}
+	return ed.Kill(c.command, sig)
 }
 
 func (daemon *Daemon) Stats(c *Container) (*execdriver.ResourceStats, error) {
-	return daemon.execDriver.Stats(c.ID)
+	ed, err := c.execDriver()
+	if err != nil {
+		return nil, err
+	}
+	return ed.Stats(c.ID)
 }
 
 func (daemon *Daemon) SubscribeToContainerStats(name string) (chan interface{}, error) {
@@ -1178,8 +1260,12 @@ func (daemon *Daemon) verifyHostConfig(hostConfig *runconfig.HostConfig) ([]stri
 		return warnings, nil
 	}
 
-	if hostConfig.LxcConf.Len() > 0 && !strings.Contains(daemon.ExecutionDriver().Name(), "lxc") {
-		return warnings, fmt.Errorf("Cannot use --lxc-conf with execdriver: %s", daemon.ExecutionDriver().Name())
+	ed, err := daemon.getExecDriver(hostConfig.ExecDriver)
+	if err != nil {
+		return warnings, err
+	}
+	if hostConfig.LxcConf.Len() > 0 && !strings.Contains(ed.Name(), "lxc") {
+		return warnings, fmt.Errorf("Cannot use --lxc-conf with execdriver: %s", ed.Name())
 	}
 	if hostConfig.Memory != 0 && hostConfig.Memory < 4194304 {
 		return warnings, fmt.Errorf("Minimum memory limit allowed is 4MB")
@@ -1198,6 +1284,18 @@ func (daemon *Daemon) verifyHostConfig(hostConfig *runconfig.HostConfig) ([]stri
 	if hostConfig.Memory == 0 && hostConfig.MemorySwap > 0 {
 		return warnings, fmt.Errorf("You should always set the Memory limit when using Memoryswap limit, see usage.")
 	}
+	if hostConfig.MemoryHigh > 0 && !daemon.SystemConfig().MemoryLimit {
+		warnings = append(warnings, "Your kernel does not support memory limit capabilities. Memory high watermark discarded.")
+		hostConfig.MemoryHigh = 0
+	}
+	if hostConfig.MemoryHigh > 0 && hostConfig.Memory > 0 && hostConfig.MemoryHigh > hostConfig.Memory {
+		return warnings, fmt.Errorf("Memory high watermark should be smaller than the memory limit, see usage.")
+	}
+	if hostConfig.MemoryHigh > 0 {
+		if err := execdriver.CheckFeature(ed, execdriver.FeatureMemoryHigh); err != nil {
+			return warnings, err
+		}
+	}
 	if hostConfig.CpuPeriod > 0 && !daemon.SystemConfig().CpuCfsPeriod {
 		warnings = append(warnings, "Your kernel does not support CPU cfs period. Period discarded.")
 		hostConfig.CpuPeriod = 0
@@ -1213,6 +1311,69 @@ func (daemon *Daemon) verifyHostConfig(hostConfig *runconfig.HostConfig) ([]stri
 		hostConfig.OomKillDisable = false
 		return warnings, fmt.Errorf("Your kernel does not support oom kill disable.")
 	}
+	if hostConfig.OomScoreAdj < -1000 || hostConfig.OomScoreAdj > 1000 {
+		return warnings, fmt.Errorf("Invalid value %d, range for oom score adj is [-1000, 1000].", hostConfig.OomScoreAdj)
+	}
+	if len(hostConfig.BlkioReadBps) > 0 || len(hostConfig.BlkioWriteBps) > 0 || len(hostConfig.BlkioReadIOps) > 0 || len(hostConfig.BlkioWriteIOps) > 0 {
+		if err := execdriver.CheckFeature(ed, execdriver.FeatureBlkioLimits); err != nil {
+			return warnings, err
+		}
+	}
+	if hostConfig.NetClassID != 0 || len(hostConfig.NetPrioMap) > 0 {
+		if err := execdriver.CheckFeature(ed, execdriver.FeatureNetClass); err != nil {
+			return warnings, err
+		}
+	}
+	if len(hostConfig.Tmpfs) > 0 {
+		if err := execdriver.CheckFeature(ed, execdriver.FeatureTmpfs); err != nil {
+			return warnings, err
+		}
+		for _, t := range hostConfig.Tmpfs {
+			if !filepath.IsAbs(t.Path) || t.Size < 0 || t.Mode&^07777 != 0 {
+				return warnings, fmt.Errorf("Invalid tmpfs %s, expected an absolute path, a positive size and a mode up to 07777", t.Path)
+			}
+		}
+	}
+	if hostConfig.Init {
+		if err := execdriver.CheckFeature(ed, execdriver.FeatureInit); err != nil {
+			return warnings, err
+		}
+	}
+	if hostConfig.ShmSize != 0 {
+		if err := execdriver.CheckFeature(ed, execdriver.FeatureShmSize); err != nil {
+			return warnings, err
+		}
+		if hostConfig.ShmSize < 0 {
+			return warnings, fmt.Errorf("Invalid /dev/shm size %d, must be greater than 0.", hostConfig.ShmSize)
+		}
+		if !hostConfig.IpcMode.IsPrivate() {
+			return warnings, fmt.Errorf("Cannot set the size of /dev/shm of a container sharing the IPC namespace of another.")
+		}
+	}
+	// catch unknown capabilities before the driver starts the container
+	if _, err := execdriver.TweakCapabilities(nil, hostConfig.CapAdd, hostConfig.CapDrop); err != nil {
+		return warnings, err
+	}
+	if hostConfig.CpuRtPolicy != "" {
+		if err := execdriver.CheckFeature(ed, execdriver.FeatureRealtime); err != nil {
+			return warnings, err
+		}
+		if hostConfig.CpuRtPolicy != "fifo" && hostConfig.CpuRtPolicy != "rr" {
+			return warnings, fmt.Errorf("Invalid real-time scheduling policy %s, must be fifo or rr.", hostConfig.CpuRtPolicy)
+		}
+		if hostConfig.CpuRtPriority < 1 || hostConfig.CpuRtPriority > 99 {
+			return warnings, fmt.Errorf("Range of real-time priority is from 1 to 99.")
+		}
+	}
+	if hostConfig.CpuRtRuntime > 0 && hostConfig.CpuRtPolicy == "" {
+		return warnings, fmt.Errorf("You should always set the real-time policy when using a real-time runtime.")
+	}
+	if hostConfig.CpuRtPeriod > 0 && hostConfig.CpuRtPolicy == "" {
+		return warnings, fmt.Errorf("You should always set the real-time policy when using a real-time period.")
+	}
+	if hostConfig.CpuRtPeriod > 0 && hostConfig.CpuRtRuntime > hostConfig.CpuRtPeriod {
+		return warnings, fmt.Errorf("The real-time runtime cannot be greater than the real-time period.")
+	}
 
 	return warnings, nil
 }
@@ -1230,6 +1391,11 @@ func (daemon *Daemon) setHostConfig(container *Container, hostConfig *runconfig.
 	}
 
 	container.hostConfig = hostConfig
+	ed, err := container.execDriver()
+	if err != nil {
+		return err
+	}
+	container.ExecDriver = ed.Name()
 	container.toDisk()
 
 	return nil
@@ -152,7 +152,11 @@ func (daemon *Daemon) commonRm(container *Container, forceRemove bool) (err erro
 		return fmt.Errorf("Unable to remove filesystem for %v: %v", container.ID, err)
 	}
 
-	if err = daemon.execDriver.Clean(container.ID); err != nil {
+	ed, err := container.execDriver()
+	if err != nil {
+		return fmt.Errorf("Unable to remove execdriver data for %s: %s", container.ID, err)
+	}
+	if err = ed.Clean(container.ID); err != nil {
 		return fmt.Errorf("Unable to remove execdriver data for %s: %s", container.ID, err)
 	}
 
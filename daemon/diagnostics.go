@@ -0,0 +1,107 @@
+package daemon
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/docker/docker/daemon/execdriver"
+)
+
+// ContainerDiagnostics writes a tar archive with everything useful to debug
+// a container to out: its configuration, the last logTail bytes of its
+// output and the artifacts collected by the exec driver.
+func (daemon *Daemon) ContainerDiagnostics(name string, logTail int64, out io.Writer) error {
+	container, err := daemon.Get(name)
+	if err != nil {
+		return err
+	}
+
+	files := make(map[string][]byte)
+	for _, file := range []string{"config.json", "hostconfig.json"} {
+		path, err := container.GetRootResourcePath(file)
+		if err != nil {
+			return err
+		}
+		if data, err := ioutil.ReadFile(path); err == nil {
+			files[file] = data
+		}
+	}
+	if container.LogPath != "" {
+		data, err := readTail(container.LogPath, logTail)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		files["stdio.log"] = data
+	}
+	ed, err := container.execDriver()
+	if err != nil {
+		return err
+	}
+	if d, ok := ed.(execdriver.Diagnoser); ok {
+		driverFiles, err := d.Diagnostics(container.ID)
+		if err != nil {
+			return fmt.Errorf("%s: %s", name, err)
+		}
+		for file, data := range driverFiles {
+			files[filepath.Join("execdriver", file)] = data
+		}
+	}
+	// the startup log of a stopped container is already in the driver files
+	if d, ok := ed.(execdriver.Debugger); ok && container.IsRunning() {
+		if data, err := d.Debug(container.ID); err == nil {
+			files[filepath.Join("execdriver", "debug.txt")] = data
+		}
+	}
+	return writeDiagnostics(out, container.ID, files)
+}
+
+func writeDiagnostics(out io.Writer, id string, files map[string][]byte) error {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := time.Now()
+	tw := tar.NewWriter(out)
+	for _, name := range names {
+		hdr := &tar.Header{
+			Name:    filepath.Join(id, name),
+			Mode:    0600,
+			Size:    int64(len(files[name])),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// readTail returns at most the last n bytes of the file at path.
+func readTail(path string, n int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if n > 0 && fi.Size() > n {
+		if _, err := f.Seek(-n, os.SEEK_END); err != nil {
+			return nil, err
+		}
+	}
+	return ioutil.ReadAll(f)
+}
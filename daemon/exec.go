@@ -20,6 +20,7 @@ type execConfig struct {
 	ID            string
 	Running       bool
 	ExitCode      int
+	Pid           int // host pid of the process while it runs
 	ProcessConfig execdriver.ProcessConfig
 	StreamConfig
 	OpenStdin  bool
@@ -109,16 +110,19 @@ func (d *Daemon) getActiveContainer(name string) (*Container, error) {
 }
 
 func (d *Daemon) ContainerExecCreate(config *runconfig.ExecConfig) (string, error) {
-
-	// Not all drivers support Exec (LXC for example)
-	if err := checkExecSupport(d.execDriver.Name()); err != nil {
+	container, err := d.getActiveContainer(config.Container)
+	if err != nil {
 		return "", err
 	}
 
-	container, err := d.getActiveContainer(config.Container)
+	ed, err := container.execDriver()
 	if err != nil {
 		return "", err
 	}
+	// Not all drivers support Exec (LXC for example)
+	if err := execdriver.CheckFeature(ed, execdriver.FeatureExec); err != nil {
+		return "", err
+	}
 
 	cmd := runconfig.NewCommand(config.Cmd...)
 	entrypoint, args := d.getEntrypointAndArgs(runconfig.NewEntrypoint(), cmd)
@@ -130,6 +134,16 @@ func (d *Daemon) ContainerExecCreate(config *runconfig.ExecConfig) (string, erro
 		User:       config.User,
 		Privileged: config.Privileged,
 	}
+	if config.Memory != 0 || config.CpuShares != 0 || config.CpuQuota != 0 {
+		if config.Memory != 0 && config.Memory < 4194304 {
+			return "", fmt.Errorf("Minimum memory limit allowed is 4MB")
+		}
+		processConfig.Resources = &execdriver.Resources{
+			Memory:    config.Memory,
+			CpuShares: config.CpuShares,
+			CpuQuota:  config.CpuQuota,
+		}
+	}
 
 	execConfig := &execConfig{
 		ID:            stringid.GenerateRandomID(),
@@ -232,7 +246,11 @@ func (d *Daemon) ContainerExecStart(execName string, stdin io.ReadCloser, stdout
 }
 
 func (d *Daemon) Exec(c *Container, execConfig *execConfig, pipes *execdriver.Pipes, startCallback execdriver.StartCallback) (int, error) {
-	exitStatus, err := d.execDriver.Exec(c.command, &execConfig.ProcessConfig, pipes, startCallback)
+	var exitStatus int
+	ed, err := c.execDriver()
+	if err == nil {
+		exitStatus, err = ed.Exec(c.command, &execConfig.ProcessConfig, pipes, startCallback)
+	}
 
 	// On err, make sure we don't leave ExitCode at zero
 	if err != nil && exitStatus == 0 {
@@ -241,6 +259,7 @@ func (d *Daemon) Exec(c *Container, execConfig *execConfig, pipes *execdriver.Pi
 
 	execConfig.ExitCode = exitStatus
 	execConfig.Running = false
+	execConfig.Pid = 0
 
 	return exitStatus, err
 }
@@ -0,0 +1,129 @@
+package execdriver
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"syscall"
+	"time"
+)
+
+// Latencies summarizes a set of timings by percentile.
+type Latencies struct {
+	P50 time.Duration `json:"p50"`
+	P90 time.Duration `json:"p90"`
+	P99 time.Duration `json:"p99"`
+	Max time.Duration `json:"max"`
+}
+
+// BenchmarkResult is the outcome of driving a number of start/stop cycles
+// through a driver.
+type BenchmarkResult struct {
+	Driver   string    `json:"driver"`
+	Cycles   int       `json:"cycles"`
+	Failures int       `json:"failures"`
+	Start    Latencies `json:"start"`
+	Stop     Latencies `json:"stop"`
+	// The highest memory usage reported by the cgroup of any benchmarked
+	// container, used as an estimate of the per container overhead.
+	MaxMemoryUsage uint64 `json:"max_memory_usage"`
+}
+
+// Benchmark runs n create/start/stop cycles through the driver and reports
+// timing percentiles for the start and stop phases. newCommand is called
+// once per cycle and must return a fully populated Command for a container
+// that keeps running until it is killed.
+func Benchmark(d Driver, n int, newCommand func(cycle int) *Command) (*BenchmarkResult, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid number of benchmark cycles %d", n)
+	}
+	var (
+		starts, stops []time.Duration
+		result        = &BenchmarkResult{Driver: d.Name(), Cycles: n}
+	)
+	for i := 0; i < n; i++ {
+		start, stop, usage, err := benchmarkCycle(d, newCommand(i))
+		if err != nil {
+			result.Failures++
+			continue
+		}
+		starts = append(starts, start)
+		stops = append(stops, stop)
+		if usage > result.MaxMemoryUsage {
+			result.MaxMemoryUsage = usage
+		}
+	}
+	result.Start = computeLatencies(starts)
+	result.Stop = computeLatencies(stops)
+	return result, nil
+}
+
+func benchmarkCycle(d Driver, c *Command) (start, stop time.Duration, usage uint64, err error) {
+	var (
+		started = make(chan struct{})
+		exited  = make(chan error, 1)
+		pipes   = NewPipes(nil, ioutil.Discard, ioutil.Discard, false)
+		begin   = time.Now()
+	)
+	go func() {
+		_, err := d.Run(c, pipes, func(*ProcessConfig, int) {
+			close(started)
+		})
+		exited <- err
+	}()
+	select {
+	case <-started:
+		start = time.Since(begin)
+	case err := <-exited:
+		if err == nil {
+			err = fmt.Errorf("container %s exited before it was started", c.ID)
+		}
+		return 0, 0, 0, err
+	}
+
+	if stats, err := d.Stats(c.ID); err == nil && stats.CgroupStats != nil {
+		usage = stats.CgroupStats.MemoryStats.Usage
+	}
+
+	begin = time.Now()
+	if err := d.Kill(c, int(syscall.SIGKILL)); err != nil {
+		d.Terminate(c)
+		<-exited
+		return 0, 0, 0, err
+	}
+	if err := <-exited; err != nil {
+		return 0, 0, 0, err
+	}
+	return start, time.Since(begin), usage, nil
+}
+
+func computeLatencies(durations []time.Duration) Latencies {
+	if len(durations) == 0 {
+		return Latencies{}
+	}
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Sort(byDuration(sorted))
+	return Latencies{
+		P50: percentile(sorted, 0.50),
+		P90: percentile(sorted, 0.90),
+		P99: percentile(sorted, 0.99),
+		Max: sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+type byDuration []time.Duration
+
+func (d byDuration) Len() int           { return len(d) }
+func (d byDuration) Less(i, j int) bool { return d[i] < d[j] }
+func (d byDuration) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
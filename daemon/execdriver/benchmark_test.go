@@ -0,0 +1,90 @@
+package execdriver
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestComputeLatencies(t *testing.T) {
+	var durations []time.Duration
+	for i := 100; i > 0; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+
+	l := computeLatencies(durations)
+	if l.P50 != 50*time.Millisecond {
+		t.Fatalf("expected p50 of 50ms got %s", l.P50)
+	}
+	if l.P90 != 90*time.Millisecond {
+		t.Fatalf("expected p90 of 90ms got %s", l.P90)
+	}
+	if l.P99 != 99*time.Millisecond {
+		t.Fatalf("expected p99 of 99ms got %s", l.P99)
+	}
+	if l.Max != 100*time.Millisecond {
+		t.Fatalf("expected max of 100ms got %s", l.Max)
+	}
+	if durations[0] != 100*time.Millisecond {
+		t.Fatal("input durations should not be reordered")
+	}
+}
+
+func TestComputeLatenciesEmpty(t *testing.T) {
+	if l := computeLatencies(nil); l != (Latencies{}) {
+		t.Fatalf("expected zero latencies got %v", l)
+	}
+}
+
+// benchmarkDriver starts the containers until they are killed, failing to
+// start the ones in fail.
+type benchmarkDriver struct {
+	Driver
+	fail   map[string]bool
+	killed map[string]chan struct{}
+}
+
+func (d *benchmarkDriver) Name() string {
+	return "benchmark"
+}
+
+func (d *benchmarkDriver) Run(c *Command, pipes *Pipes, startCallback StartCallback) (ExitStatus, error) {
+	if d.fail[c.ID] {
+		return ExitStatus{ExitCode: -1}, fmt.Errorf("cannot start %s", c.ID)
+	}
+	killed := make(chan struct{})
+	d.killed[c.ID] = killed
+	startCallback(&c.ProcessConfig, 1)
+	<-killed
+	return ExitStatus{ExitCode: 137}, nil
+}
+
+func (d *benchmarkDriver) Stats(id string) (*ResourceStats, error) {
+	return nil, ErrNotRunning
+}
+
+func (d *benchmarkDriver) Kill(c *Command, sig int) error {
+	close(d.killed[c.ID])
+	return nil
+}
+
+func TestBenchmark(t *testing.T) {
+	d := &benchmarkDriver{fail: map[string]bool{"1": true}, killed: make(map[string]chan struct{})}
+	result, err := Benchmark(d, 3, func(cycle int) *Command {
+		return &Command{ID: strconv.Itoa(cycle)}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Driver != "benchmark" || result.Cycles != 3 || result.Failures != 1 {
+		t.Fatalf("expected 3 cycles with 1 failure got %+v", result)
+	}
+	if result.Start.Max == 0 || result.Stop.Max == 0 {
+		t.Fatalf("expected the latencies of the successful cycles got %+v", result)
+	}
+
+	if _, err := Benchmark(d, 0, nil); err == nil {
+		t.Fatal("expected an error for no cycles")
+	}
+}
@@ -0,0 +1,85 @@
+package execdriver
+
+import "fmt"
+
+// APIVersion is the version of the Driver interface implemented by this
+// daemon.  It is only bumped when the methods every driver must implement
+// change.  Optional operations are not versioned: drivers report them as
+// features and implement the optional interfaces of this package.
+const APIVersion = 1
+
+// Feature is an optional operation that a driver may support.
+type Feature string
+
+const (
+	FeatureExec        Feature = "exec"
+	FeaturePause       Feature = "pause"
+	FeatureStats       Feature = "stats"
+	FeatureDiagnostics Feature = "diagnostics"
+	FeatureCheckpoint  Feature = "checkpoint"
+	FeatureUpdate      Feature = "update"
+	FeatureRealtime    Feature = "realtime"
+	FeatureMemoryHigh  Feature = "memory-high"
+	FeatureAddDevice   Feature = "add-device"
+	FeatureBlkioLimits Feature = "blkio-limits"
+	FeatureNetClass    Feature = "net-class"
+	FeatureSignal      Feature = "signal-process"
+	FeatureKillPaused  Feature = "kill-paused"
+	FeatureMetrics     Feature = "metrics"
+	FeatureTmpfs       Feature = "tmpfs"
+	FeatureShmSize     Feature = "shm-size"
+	FeatureInit        Feature = "init"
+)
+
+// DriverCapabilities describes the interface version and features of a driver.
+type DriverCapabilities struct {
+	Version  int
+	Features []Feature
+}
+
+// Has returns true if the feature is supported.
+func (c DriverCapabilities) Has(f Feature) bool {
+	for _, feature := range c.Features {
+		if feature == f {
+			return true
+		}
+	}
+	return false
+}
+
+// CapabilityReporter is implemented by drivers that report their capabilities.
+// Drivers that do not implement it are assumed to predate the handshake.
+type CapabilityReporter interface {
+	DriverCapabilities() DriverCapabilities
+}
+
+// legacyCapabilities are assumed for drivers that do not report their
+// capabilities: version 0 only has the operations of the original interface.
+var legacyCapabilities = DriverCapabilities{
+	Version:  0,
+	Features: []Feature{FeatureExec, FeaturePause, FeatureStats},
+}
+
+// GetDriverCapabilities returns the capabilities of the driver.
+func GetDriverCapabilities(d Driver) DriverCapabilities {
+	if r, ok := d.(CapabilityReporter); ok {
+		return r.DriverCapabilities()
+	}
+	caps := DriverCapabilities{
+		Version:  legacyCapabilities.Version,
+		Features: append([]Feature(nil), legacyCapabilities.Features...),
+	}
+	if _, ok := d.(Diagnoser); ok {
+		caps.Features = append(caps.Features, FeatureDiagnostics)
+	}
+	return caps
+}
+
+// CheckFeature returns an error if the driver does not support the feature,
+// so that callers fail before calling into the driver.
+func CheckFeature(d Driver, f Feature) error {
+	if !GetDriverCapabilities(d).Has(f) {
+		return fmt.Errorf("Unsupported: %s is not supported by the %s driver", f, d.Name())
+	}
+	return nil
+}
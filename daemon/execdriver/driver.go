@@ -2,13 +2,16 @@ package execdriver
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"os/exec"
+	"syscall"
 	"time"
 
 	// TODO Windows: Factor out ulimit
 	"github.com/docker/docker/pkg/ulimit"
 	"github.com/docker/libcontainer"
+	"github.com/docker/libcontainer/cgroups"
 	"github.com/docker/libcontainer/configs"
 )
 
@@ -31,6 +34,34 @@ type Info interface {
 	IsRunning() bool
 }
 
+// NetClassReporter is implemented by the Info of drivers that classify the
+// network traffic of containers.
+type NetClassReporter interface {
+	// NetClass returns the net_cls class id and the priorities per interface.
+	NetClass() (uint32, map[string]int, error)
+}
+
+// RlimitReporter is implemented by the Info of drivers that can report the
+// resource limits in effect in a running container, including the ones
+// inherited from the daemon.
+type RlimitReporter interface {
+	Rlimits() ([]*ulimit.Ulimit, error)
+}
+
+// StdioReporter is implemented by the Info of drivers that account the stdio
+// of containers.
+type StdioReporter interface {
+	StdioStats() (StdioStats, error)
+}
+
+// StdioStats are the bytes copied between the stdio of a container and its
+// clients.  With a TTY the output is all counted as stdout.
+type StdioStats struct {
+	StdinBytes  uint64 `json:"stdin_bytes"`
+	StdoutBytes uint64 `json:"stdout_bytes"`
+	StderrBytes uint64 `json:"stderr_bytes"`
+}
+
 // Terminal in an interface for drivers to implement
 // if they want to support Close and Resize calls from
 // the core
@@ -43,6 +74,147 @@ type TtyTerminal interface {
 	Master() libcontainer.Console
 }
 
+// Diagnoser is implemented by drivers that can collect driver specific
+// debugging artifacts for a container.
+type Diagnoser interface {
+	// Diagnostics returns the contents of diagnostic files keyed by file name.
+	Diagnostics(id string) (map[string][]byte, error)
+}
+
+// StatsStreamer is implemented by drivers that collect the stats of a
+// container themselves and send them to the subscribers.
+type StatsStreamer interface {
+	// StatsStream returns a channel receiving the stats every interval,
+	// closed when the container exits, and a function stopping the stream.
+	StatsStream(id string, interval time.Duration) (<-chan *ResourceStats, func(), error)
+}
+
+// StatsAller is implemented by drivers that collect the stats of all running
+// containers at once.
+type StatsAller interface {
+	// StatsAll returns the stats of the running containers by id.
+	StatsAll() (map[string]*ResourceStats, error)
+}
+
+// Debugger is implemented by drivers that can describe a container from
+// inside its namespaces.
+type Debugger interface {
+	Debug(id string) ([]byte, error)
+}
+
+// Profiler is implemented by drivers that can profile a container with perf.
+type Profiler interface {
+	// PerfCommand returns the perf subcommand with args, not started,
+	// scoped to the perf_event cgroup of the container.
+	PerfCommand(id, subcommand string, args ...string) (*exec.Cmd, error)
+}
+
+// Resizer is implemented by drivers that keep the size of a container's
+// console requested before the console is created, so that it is not lost.
+type Resizer interface {
+	Resize(id string, height, width int) error
+}
+
+// MetricsReporter is implemented by drivers that instrument their operations.
+type MetricsReporter interface {
+	// Metrics returns a snapshot of the metrics of the driver.
+	Metrics() []Metric
+}
+
+// StatusReporter is implemented by drivers that report low level status
+// for docker info.
+type StatusReporter interface {
+	Status() [][2]string
+}
+
+// Stopper is implemented by drivers that stop containers themselves, sending
+// the stop signal and escalating to SIGKILL after the timeout.
+type Stopper interface {
+	Stop(c *Command, timeout time.Duration, stopSignal syscall.Signal) error
+}
+
+// ProcessLister is implemented by drivers that describe the processes of a
+// container themselves instead of only returning their pids.
+type ProcessLister interface {
+	GetProcesses(id string) ([]*ProcessInfo, error)
+}
+
+// ProcessInfo describes a process of a running container.
+type ProcessInfo struct {
+	Pid       int           `json:"pid"` // in the host's pid namespace
+	PPid      int           `json:"ppid"`
+	Uid       int           `json:"uid"`
+	CpuTime   time.Duration `json:"cpu_time"` // user and system time
+	Rss       uint64        `json:"rss"`      // resident memory in bytes
+	StartTime time.Time     `json:"start_time"`
+	Cmdline   string        `json:"cmdline"`
+}
+
+// ProcessSignaler is implemented by drivers that can signal any process of a
+// container, not only its init process.
+type ProcessSignaler interface {
+	// SignalProcess sends the signal to the process with the host pid,
+	// which must belong to the container.
+	SignalProcess(id string, pid int, sig syscall.Signal) error
+}
+
+// Updater is implemented by drivers that can change the resource limits of a
+// running container.
+type Updater interface {
+	Update(id string, resources *Resources) error
+}
+
+// StatsResetter is implemented by drivers that can reset the peak usage and
+// failure counters of a running container.
+type StatsResetter interface {
+	ResetStats(id string) error
+}
+
+// DeviceAdder is implemented by drivers that can give a running container
+// access to a host device.
+type DeviceAdder interface {
+	AddDevice(id string, dev *configs.Device) error
+}
+
+// OOMNotifier is implemented by drivers that report OOM kills while the
+// container keeps running.
+type OOMNotifier interface {
+	// SubscribeOOM returns a channel receiving the OOM kills in the
+	// container, closed when the container exits.
+	SubscribeOOM(id string) (<-chan OOMEvent, error)
+}
+
+// OOMEvent is an OOM kill in a running container.
+type OOMEvent struct {
+	Time        time.Time `json:"time"`
+	VictimPid   int       `json:"victim_pid"`
+	VictimComm  string    `json:"victim_comm"`
+	MemoryUsage uint64    `json:"memory_usage"` // usage of the container right after the kill
+}
+
+// Reloader is implemented by drivers that can apply changed options while
+// running.
+type Reloader interface {
+	// Reload validates and applies the options.
+	Reload(options []string) error
+}
+
+// Shutdowner is implemented by drivers that change the host and undo it when
+// the daemon shuts down.
+type Shutdowner interface {
+	Shutdown() error
+}
+
+// Reattacher is implemented by drivers that keep track of the containers left
+// running by a previous instance of the daemon.
+type Reattacher interface {
+	// Restored returns true if the container was left running.
+	Restored(id string) bool
+	// Reattach waits for a restored container to exit, like Run does for a
+	// container it starts.
+	Reattach(c *Command, startCallback StartCallback) (ExitStatus, error)
+}
+
 // ExitStatus provides exit reasons for a container.
 type ExitStatus struct {
 	// The exit code with which the container exited.
@@ -50,6 +222,39 @@ type ExitStatus struct {
 
 	// Whether the container encountered an OOM.
 	OOMKilled bool
+
+	// The kernel's report of the OOM kill, if it could be found.
+	OOMReport *OOMReport
+
+	// The signal that terminated the container, zero if it exited by itself.
+	Signal int
+
+	// What caused the container to exit, one of the Initiator constants.
+	Initiator string
+}
+
+// Initiators of the exit of a container.
+const (
+	InitiatorWorkload  = "workload"  // the container exited or crashed by itself
+	InitiatorKill      = "kill"      // killed by a signal sent through Kill
+	InitiatorTerminate = "terminate" // killed through Terminate
+	InitiatorStop      = "stop"      // stopped through Stop
+	InitiatorOOM       = "oom"       // killed by the kernel's OOM killer
+)
+
+// OOMReport is the kernel OOM killer's account of a kill in a container.
+type OOMReport struct {
+	VictimPid  int          `json:"victim_pid"`
+	VictimComm string       `json:"victim_comm"`
+	Processes  []OOMProcess `json:"processes"` // the processes of the container at the time of the kill
+	Message    string       `json:"message"`   // the raw report from the kernel log
+}
+
+// OOMProcess is an entry of the process table dumped by the OOM killer.
+type OOMProcess struct {
+	Pid  int    `json:"pid"`
+	Comm string `json:"comm"`
+	RSS  uint64 `json:"rss"` // resident set size in pages
 }
 
 type Driver interface {
@@ -80,16 +285,19 @@ type Network struct {
 type Ipc struct {
 	ContainerID string `json:"container_id"` // id of the container to join ipc.
 	HostIpc     bool   `json:"host_ipc"`
+	ShmSize     int64  `json:"shm_size"` // size of /dev/shm in bytes, the driver's default if zero.
 }
 
 // PID settings of the container
 type Pid struct {
-	HostPid bool `json:"host_pid"`
+	ContainerID string `json:"container_id"` // id of the container to join pid.
+	HostPid     bool   `json:"host_pid"`
 }
 
 // UTS settings of the container
 type UTS struct {
-	HostUTS bool `json:"host_uts"`
+	HostUTS    bool   `json:"host_uts"`
+	Domainname string `json:"domainname"` // domainname of the container, unless it uses the host's UTS.
 }
 
 type NetworkInterface struct {
@@ -107,23 +315,60 @@ type NetworkInterface struct {
 
 // TODO Windows: Factor out ulimit.Rlimit
 type Resources struct {
-	Memory         int64            `json:"memory"`
-	MemorySwap     int64            `json:"memory_swap"`
-	CpuShares      int64            `json:"cpu_shares"`
-	CpusetCpus     string           `json:"cpuset_cpus"`
-	CpusetMems     string           `json:"cpuset_mems"`
-	CpuPeriod      int64            `json:"cpu_period"`
-	CpuQuota       int64            `json:"cpu_quota"`
-	BlkioWeight    int64            `json:"blkio_weight"`
-	Rlimits        []*ulimit.Rlimit `json:"rlimits"`
-	OomKillDisable bool             `json:"oom_kill_disable"`
+	Memory         int64             `json:"memory"`
+	MemorySwap     int64             `json:"memory_swap"`
+	CpuShares      int64             `json:"cpu_shares"`
+	CpusetCpus     string            `json:"cpuset_cpus"`
+	CpusetMems     string            `json:"cpuset_mems"`
+	CpuPeriod      int64             `json:"cpu_period"`
+	CpuQuota       int64             `json:"cpu_quota"`
+	BlkioWeight    int64             `json:"blkio_weight"`
+	Rlimits        []*ulimit.Rlimit  `json:"rlimits"`
+	OomKillDisable bool              `json:"oom_kill_disable"`
+	CpuRtRuntime   int64             `json:"cpu_rt_runtime"` // real-time budget in microseconds per period
+	CpuRtPeriod    int64             `json:"cpu_rt_period"`  // real-time period in microseconds
+	BlkioReadBps   []*ThrottleDevice `json:"blkio_read_bps"`
+	BlkioWriteBps  []*ThrottleDevice `json:"blkio_write_bps"`
+	BlkioReadIOps  []*ThrottleDevice `json:"blkio_read_iops"`
+	BlkioWriteIOps []*ThrottleDevice `json:"blkio_write_iops"`
+	MemoryHigh     int64             `json:"memory_high"` // usage above which the container is throttled and reclaimed
+}
+
+// ThrottleDevice limits the IO of a block device, in bytes or in operations
+// per second.
+type ThrottleDevice struct {
+	Major int64  `json:"major"`
+	Minor int64  `json:"minor"`
+	Rate  uint64 `json:"rate"`
+}
+
+// String returns the limit in the format of the blkio.throttle cgroup files.
+func (t *ThrottleDevice) String() string {
+	return fmt.Sprintf("%d:%d %d", t.Major, t.Minor, t.Rate)
 }
 
 type ResourceStats struct {
 	*libcontainer.Stats
-	Read        time.Time `json:"read"`
-	MemoryLimit int64     `json:"memory_limit"`
-	SystemUsage uint64    `json:"system_usage"`
+	Read        time.Time  `json:"read"`
+	MemoryLimit int64      `json:"memory_limit"`
+	SystemUsage uint64     `json:"system_usage"`
+	ExecStats   *ExecStats `json:"exec_stats"`
+
+	// IO of the container counted by the blkio throttling policy, which
+	// unlike the CFQ counters includes devices using other IO schedulers
+	BlkioThrottleServiceBytes []cgroups.BlkioStatEntry `json:"blkio_throttle_service_bytes"`
+	BlkioThrottleServiced     []cgroups.BlkioStatEntry `json:"blkio_throttle_serviced"`
+
+	OomKillDisable bool `json:"oom_kill_disable"`
+	OomScoreAdj    int  `json:"oom_score_adj"`
+}
+
+// ExecStats is the resource usage of the processes exec'd in a container with
+// resources of their own.  It is included in the usage of the container.
+type ExecStats struct {
+	Sessions    int    `json:"sessions"`     // number of running exec'd processes
+	CpuUsage    uint64 `json:"cpu_usage"`    // in nanoseconds
+	MemoryUsage uint64 `json:"memory_usage"` // in bytes
 }
 
 type Mount struct {
@@ -132,6 +377,17 @@ type Mount struct {
 	Writable    bool   `json:"writable"`
 	Private     bool   `json:"private"`
 	Slave       bool   `json:"slave"`
+	// Propagation of the mount in the container's mount namespace: shared,
+	// rshared, slave, rslave, private or rprivate.  The driver's default is
+	// used when empty.
+	Propagation string `json:"propagation"`
+}
+
+// TmpfsMount is a tmpfs mounted in the container.
+type TmpfsMount struct {
+	Destination string `json:"destination"`
+	Size        int64  `json:"size"` // in bytes, the kernel's default if 0
+	Mode        uint32 `json:"mode"` // 1777 if 0
 }
 
 // Describes a process that will be run inside a container.
@@ -145,6 +401,11 @@ type ProcessConfig struct {
 	Arguments  []string `json:"arguments"`
 	Terminal   Terminal `json:"-"` // standard or tty terminal
 	Console    string   `json:"-"` // dev/console path
+
+	// Resources of a process exec'd in a container, the process runs in a
+	// cgroup of its own under the container's when set.  Only Memory,
+	// CpuShares and CpuQuota are used.
+	Resources *Resources `json:"resources"`
 }
 
 // TODO Windows: Factor out unused fields such as LxcConfig, AppArmorProfile,
@@ -164,6 +425,7 @@ type Command struct {
 	UTS                *UTS              `json:"uts"`
 	Resources          *Resources        `json:"resources"`
 	Mounts             []Mount           `json:"mounts"`
+	Tmpfs              []TmpfsMount      `json:"tmpfs"`
 	AllowedDevices     []*configs.Device `json:"allowed_devices"`
 	AutoCreatedDevices []*configs.Device `json:"autocreated_devices"`
 	CapAdd             []string          `json:"cap_add"`
@@ -175,4 +437,17 @@ type Command struct {
 	LxcConfig          []string          `json:"lxc_config"`
 	AppArmorProfile    string            `json:"apparmor_profile"`
 	CgroupParent       string            `json:"cgroup_parent"` // The parent cgroup for this command.
+	DebugStart         bool              `json:"debug_start"`   // Log the startup of the init process to a file.
+	RtPolicy           string            `json:"rt_policy"`     // Real-time scheduling policy of the container's processes, fifo or rr.
+	RtPriority         int               `json:"rt_priority"`   // Real-time priority, between 1 and 99.
+	OomScoreAdj        int               `json:"oom_score_adj"` // OOM score adjustment of the container's processes.
+	NetClassID         uint32            `json:"net_class_id"`  // net_cls class id of the container's traffic.
+	NetPrioMap         map[string]int    `json:"net_prio_map"`  // Priority of the container's traffic per interface.
+	// Paths of the container masked, and remounted read only, from its
+	// processes.  The driver's defaults are used when nil.
+	MaskPaths     []string `json:"mask_paths"`
+	ReadonlyPaths []string `json:"readonly_paths"`
+	// Init runs an init as the pid 1 of the container that forwards signals
+	// to the process of the container and reaps zombies.
+	Init bool `json:"init"`
 }
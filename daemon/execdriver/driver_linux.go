@@ -57,6 +57,9 @@ func SetupCgroups(container *configs.Config, c *Command) error {
 		container.Cgroups.CpuQuota = c.Resources.CpuQuota
 		container.Cgroups.BlkioWeight = c.Resources.BlkioWeight
 		container.Cgroups.OomKillDisable = c.Resources.OomKillDisable
+		if c.Resources.MemoryHigh > 0 {
+			container.Cgroups.MemoryReservation = c.Resources.MemoryHigh
+		}
 	}
 
 	return nil
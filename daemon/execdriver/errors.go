@@ -0,0 +1,45 @@
+package execdriver
+
+import "fmt"
+
+// ErrContainerNotActive is returned when the driver has no running container
+// with the ID, for example because it exited in the meantime.
+type ErrContainerNotActive struct {
+	ID string
+}
+
+func (e *ErrContainerNotActive) Error() string {
+	return fmt.Sprintf("active container for %s does not exist", e.ID)
+}
+
+// ErrContainerPaused is returned for operations that are not possible while
+// the container is paused.
+type ErrContainerPaused struct {
+	ID string
+}
+
+func (e *ErrContainerPaused) Error() string {
+	return fmt.Sprintf("container %s is paused", e.ID)
+}
+
+// ErrCgroupFailure is returned when the driver fails to operate on the
+// cgroups of a running container.
+type ErrCgroupFailure struct {
+	ID  string
+	Err error
+}
+
+func (e *ErrCgroupFailure) Error() string {
+	return fmt.Sprintf("cgroup operation failed for container %s: %v", e.ID, e.Err)
+}
+
+// IsContainerStateError returns true if the error is caused by the state of
+// the container, not by a failure of the driver, so the operation may succeed
+// once the container's state changes.
+func IsContainerStateError(err error) bool {
+	switch err.(type) {
+	case *ErrContainerNotActive, *ErrContainerPaused:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+package execdriver
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIsContainerStateError(t *testing.T) {
+	for _, tc := range []struct {
+		err   error
+		state bool
+	}{
+		{&ErrContainerNotActive{ID: "abc"}, true},
+		{&ErrContainerPaused{ID: "abc"}, true},
+		{&ErrCgroupFailure{ID: "abc", Err: errors.New("freezer not found")}, false},
+		{ErrNotRunning, false},
+	} {
+		if got := IsContainerStateError(tc.err); got != tc.state {
+			t.Errorf("IsContainerStateError(%q) = %v, want %v", tc.err, got, tc.state)
+		}
+	}
+}
@@ -3,17 +3,17 @@
 package execdrivers
 
 import (
-	"fmt"
-
 	"github.com/docker/docker/daemon/execdriver"
-	"github.com/docker/docker/daemon/execdriver/windows"
+	// register the drivers available on windows
+	_ "github.com/docker/docker/daemon/execdriver/windows"
 	"github.com/docker/docker/pkg/sysinfo"
 )
 
 func NewDriver(name string, options []string, root, libPath, initPath string, sysInfo *sysinfo.SysInfo) (execdriver.Driver, error) {
-	switch name {
-	case "windows":
-		return windows.NewDriver(root, initPath)
-	}
-	return nil, fmt.Errorf("unknown exec driver %s", name)
+	return execdriver.GetDriver(name, &execdriver.DriverConfig{
+		Root:     root,
+		LibPath:  libPath,
+		InitPath: initPath,
+		Options:  options,
+	})
 }
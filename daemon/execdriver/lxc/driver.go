@@ -54,6 +54,15 @@ type activeContainer struct {
 	cmd       *exec.Cmd
 }
 
+func init() {
+	execdriver.Register(DriverName, func(config *execdriver.DriverConfig) (execdriver.Driver, error) {
+		// we want to give the lxc driver the full docker root because it needs
+		// to access and write config and template files in /var/lib/docker/containers/*
+		// to be backwards compatible
+		return NewDriver(config.Root, config.LibPath, config.InitPath, config.AppArmor)
+	})
+}
+
 func NewDriver(root, libPath, initPath string, apparmor bool) (*driver, error) {
 	if err := os.MkdirAll(root, 0700); err != nil {
 		return nil, err
@@ -77,6 +86,13 @@ func NewDriver(root, libPath, initPath string, apparmor bool) (*driver, error) {
 	}, nil
 }
 
+func (d *driver) DriverCapabilities() execdriver.DriverCapabilities {
+	return execdriver.DriverCapabilities{
+		Version:  execdriver.APIVersion,
+		Features: []execdriver.Feature{execdriver.FeaturePause, execdriver.FeatureStats},
+	}
+}
+
 func (d *driver) Name() string {
 	version := d.version()
 	return fmt.Sprintf("%s-%s", DriverName, version)
@@ -0,0 +1,172 @@
+package execdriver
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MetricType is the type of a metric in the Prometheus text format.
+type MetricType string
+
+const (
+	MetricCounter   MetricType = "counter"
+	MetricGauge     MetricType = "gauge"
+	MetricHistogram MetricType = "histogram"
+)
+
+// Metric is a snapshot of a metric reported by a driver.
+type Metric struct {
+	Name    string
+	Help    string
+	Type    MetricType
+	Samples []MetricSample
+}
+
+// MetricSample is a value of a metric.  Suffix is appended to the name of the
+// metric, e.g. _bucket, _sum and _count for histograms.
+type MetricSample struct {
+	Suffix string
+	Labels [][2]string
+	Value  float64
+}
+
+// Counter is a metric that only goes up, optionally partitioned by the value
+// of a label.
+type Counter struct {
+	name, help, label string
+	mu                sync.Mutex
+	values            map[string]float64
+}
+
+// NewCounter returns a counter.  If label is empty the counter is not
+// partitioned and Inc is called with an empty value.
+func NewCounter(name, help, label string) *Counter {
+	return &Counter{
+		name:   name,
+		help:   help,
+		label:  label,
+		values: make(map[string]float64),
+	}
+}
+
+// Inc increments the counter for the value of the label.
+func (c *Counter) Inc(value string) {
+	c.mu.Lock()
+	c.values[value]++
+	c.mu.Unlock()
+}
+
+// Metric returns a snapshot of the counter.
+func (c *Counter) Metric() Metric {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := Metric{Name: c.name, Help: c.help, Type: MetricCounter}
+	if c.label == "" {
+		m.Samples = append(m.Samples, MetricSample{Value: c.values[""]})
+		return m
+	}
+	var values []string
+	for v := range c.values {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	for _, v := range values {
+		m.Samples = append(m.Samples, MetricSample{
+			Labels: [][2]string{{c.label, v}},
+			Value:  c.values[v],
+		})
+	}
+	return m
+}
+
+// Histogram counts observations in buckets of increasing upper bounds.
+type Histogram struct {
+	name, help string
+	buckets    []float64
+	mu         sync.Mutex
+	counts     []uint64 // per bucket, not cumulative
+	count      uint64
+	sum        float64
+}
+
+// NewHistogram returns a histogram with the sorted upper bounds of the
+// buckets.  Observations above the last bound are only in the total count.
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	return &Histogram{
+		name:    name,
+		help:    help,
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+}
+
+// Observe adds a value to the histogram.
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += v
+}
+
+// Metric returns a snapshot of the histogram.
+func (h *Histogram) Metric() Metric {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	m := Metric{Name: h.name, Help: h.help, Type: MetricHistogram}
+	var cumulative uint64
+	for i, bound := range h.buckets {
+		cumulative += h.counts[i]
+		m.Samples = append(m.Samples, MetricSample{
+			Suffix: "_bucket",
+			Labels: [][2]string{{"le", formatMetricValue(bound)}},
+			Value:  float64(cumulative),
+		})
+	}
+	m.Samples = append(m.Samples,
+		MetricSample{Suffix: "_bucket", Labels: [][2]string{{"le", "+Inf"}}, Value: float64(h.count)},
+		MetricSample{Suffix: "_sum", Value: h.sum},
+		MetricSample{Suffix: "_count", Value: float64(h.count)},
+	)
+	return m
+}
+
+// WriteMetrics writes the metrics in the Prometheus text format.
+func WriteMetrics(w io.Writer, metrics []Metric) error {
+	for _, m := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.Name, m.Help, m.Name, m.Type); err != nil {
+			return err
+		}
+		for _, s := range m.Samples {
+			var labels []string
+			for _, l := range s.Labels {
+				labels = append(labels, fmt.Sprintf("%s=%q", l[0], l[1]))
+			}
+			name := m.Name + s.Suffix
+			if len(labels) > 0 {
+				name += "{" + strings.Join(labels, ",") + "}"
+			}
+			if _, err := fmt.Fprintf(w, "%s %s\n", name, formatMetricValue(s.Value)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func formatMetricValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,41 @@
+package execdriver
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	starts := NewCounter("starts_failed_total", "Failed starts.", "reason")
+	starts.Inc("create")
+	starts.Inc("start")
+	starts.Inc("create")
+	ooms := NewCounter("oom_kills_total", "OOM kills.", "")
+	latency := NewHistogram("start_seconds", "Start latency.", []float64{0.1, 1})
+	latency.Observe(0.05)
+	latency.Observe(0.5)
+	latency.Observe(2)
+
+	var buf bytes.Buffer
+	if err := WriteMetrics(&buf, []Metric{starts.Metric(), ooms.Metric(), latency.Metric()}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# HELP starts_failed_total Failed starts.
+# TYPE starts_failed_total counter
+starts_failed_total{reason="create"} 2
+starts_failed_total{reason="start"} 1
+# HELP oom_kills_total OOM kills.
+# TYPE oom_kills_total counter
+oom_kills_total 0
+# HELP start_seconds Start latency.
+# TYPE start_seconds histogram
+start_seconds_bucket{le="0.1"} 1
+start_seconds_bucket{le="1"} 2
+start_seconds_bucket{le="+Inf"} 3
+start_seconds_sum 2.55
+start_seconds_count 3
+`
+	if got := buf.String(); got != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
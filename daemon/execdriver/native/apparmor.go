@@ -0,0 +1,58 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/docker/libcontainer/apparmor"
+)
+
+// appArmorProfiles lists the AppArmor profiles loaded in the kernel.
+const appArmorProfiles = "/sys/kernel/security/apparmor/profiles"
+
+// checkAppArmorProfile returns an error if the container cannot run under
+// the AppArmor profile, so that it fails to start instead of running
+// confined by another profile or not at all.
+func checkAppArmorProfile(name string) error {
+	if name == "" || name == "unconfined" {
+		return nil
+	}
+	if !apparmor.IsEnabled() {
+		return fmt.Errorf("AppArmor profile %q cannot be applied: AppArmor is not enabled on the host", name)
+	}
+	loaded, err := isAppArmorProfileLoaded(name)
+	if err != nil {
+		return err
+	}
+	if !loaded {
+		return fmt.Errorf("AppArmor profile %q is not loaded in the kernel, load it with apparmor_parser first", name)
+	}
+	return nil
+}
+
+// isAppArmorProfileLoaded returns true if the profile is loaded in the kernel.
+// Each line of the profiles file is the name of a profile followed by its
+// mode in parentheses.
+func isAppArmorProfileLoaded(name string) (bool, error) {
+	f, err := os.Open(appArmorProfiles)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := s.Text()
+		if i := strings.LastIndex(line, " ("); i >= 0 {
+			line = line[:i]
+		}
+		if line == name {
+			return true, nil
+		}
+	}
+	return false, s.Err()
+}
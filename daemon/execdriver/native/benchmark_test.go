@@ -0,0 +1,85 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/docker/libcontainer/configs"
+)
+
+// TestMain lets the test binary run as the init of the containers started
+// by the benchmarks.
+func TestMain(m *testing.M) {
+	if reexec.Init() {
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// BenchmarkStartStop drives start/stop cycles through the native driver and
+// reports the latency percentiles and the memory overhead.  It needs root
+// and a root filesystem with a sleep binary, such as an exported busybox
+// image:
+//
+//	mkdir /tmp/busybox && docker export $(docker create busybox) | tar -C /tmp/busybox -x
+//	DOCKER_BENCH_ROOTFS=/tmp/busybox go test -run NONE -bench StartStop ./daemon/execdriver/native/
+func BenchmarkStartStop(b *testing.B) {
+	rootfs := os.Getenv("DOCKER_BENCH_ROOTFS")
+	if rootfs == "" || os.Getuid() != 0 {
+		b.Skip("the benchmark needs root and DOCKER_BENCH_ROOTFS")
+	}
+	root, err := ioutil.TempDir("", "docker-native-benchmark")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	d, err := NewDriver(root, "", nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	result, err := execdriver.Benchmark(d, b.N, func(cycle int) *execdriver.Command {
+		return benchmarkCommand(rootfs, cycle)
+	})
+	b.StopTimer()
+	if err != nil {
+		b.Fatal(err)
+	}
+	if result.Failures > 0 {
+		b.Fatalf("%d of %d cycles failed", result.Failures, result.Cycles)
+	}
+	b.Logf("start: %+v", result.Start)
+	b.Logf("stop: %+v", result.Stop)
+	b.Logf("max memory usage: %d bytes", result.MaxMemoryUsage)
+}
+
+// benchmarkCommand returns a container sharing the network namespace of the
+// host that sleeps until it is killed.
+func benchmarkCommand(rootfs string, cycle int) *execdriver.Command {
+	return &execdriver.Command{
+		ID:                 fmt.Sprintf("benchmark-%d-%d", os.Getpid(), cycle),
+		Rootfs:             rootfs,
+		ReadonlyRootfs:     true,
+		Network:            &execdriver.Network{NamespacePath: "/proc/1/ns/net"},
+		Ipc:                &execdriver.Ipc{},
+		Pid:                &execdriver.Pid{},
+		UTS:                &execdriver.UTS{},
+		Resources:          &execdriver.Resources{},
+		AllowedDevices:     configs.DefaultAllowedDevices,
+		AutoCreatedDevices: configs.DefaultAutoCreatedDevices,
+		ProcessConfig: execdriver.ProcessConfig{
+			Entrypoint:  "sleep",
+			Arguments:   []string{"1000"},
+			Env:         []string{"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"},
+			SysProcAttr: &syscall.SysProcAttr{Setsid: true},
+		},
+	}
+}
@@ -0,0 +1,108 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/libcontainer"
+	"github.com/docker/libcontainer/cgroups"
+)
+
+// setBlkioLimits writes the block device limits of the resources to the
+// container's blkio cgroup.  libcontainer only takes a single device per
+// throttle file, so each device is written separately here once the
+// container is started.
+func setBlkioLimits(container libcontainer.Container, r *execdriver.Resources) error {
+	if r == nil {
+		return nil
+	}
+	limits := map[string][]*execdriver.ThrottleDevice{
+		"blkio.throttle.read_bps_device":   r.BlkioReadBps,
+		"blkio.throttle.write_bps_device":  r.BlkioWriteBps,
+		"blkio.throttle.read_iops_device":  r.BlkioReadIOps,
+		"blkio.throttle.write_iops_device": r.BlkioWriteIOps,
+	}
+	var path string
+	for file, devices := range limits {
+		if len(devices) == 0 {
+			continue
+		}
+		if path == "" {
+			state, err := container.State()
+			if err != nil {
+				return err
+			}
+			var ok bool
+			if path, ok = state.CgroupPaths["blkio"]; !ok {
+				return fmt.Errorf("blkio cgroup is not available for %s", container.ID())
+			}
+		}
+		for _, d := range devices {
+			if err := writeCgroupFile(filepath.Join(path, file), d.String()); err != nil {
+				return fmt.Errorf("set %s of %s: %v", file, container.ID(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// writeCgroupFile writes a single value to a cgroup file.  Files taking one
+// entry per device need a write for each entry.
+func writeCgroupFile(path, value string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(value)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// readBlkioThrottleStats reads an IO counter of the blkio throttling policy.
+// Its lines are "major:minor op value" with a final "Total value" line.
+func readBlkioThrottleStats(path string) ([]cgroups.BlkioStatEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []cgroups.BlkioStatEntry
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		dev := strings.SplitN(fields[0], ":", 2)
+		if len(dev) != 2 {
+			return nil, fmt.Errorf("invalid line in %s: %s", path, s.Text())
+		}
+		major, err := strconv.ParseUint(dev[0], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		minor, err := strconv.ParseUint(dev[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		value, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, cgroups.BlkioStatEntry{Major: major, Minor: minor, Op: fields[1], Value: value})
+	}
+	return entries, s.Err()
+}
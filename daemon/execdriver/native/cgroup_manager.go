@@ -0,0 +1,170 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/docker/libcontainer"
+	"github.com/docker/libcontainer/cgroups/systemd"
+	"github.com/docker/libcontainer/configs"
+)
+
+// CgroupManagerFactory provides a cgroup manager that can be selected with
+// native.cgroupdriver=<name>.
+type CgroupManagerFactory interface {
+	// Available returns false if the manager cannot be used on this host.
+	Available() bool
+	// Option returns the libcontainer factory option that sets up the
+	// manager for new containers.
+	Option() func(*libcontainer.LinuxFactory) error
+}
+
+// CgroupParentSetter is implemented by cgroup managers that check the
+// format of the parent cgroups of containers or place them differently.
+// Without it the parent is used as the path of the cgroup's parent.
+type CgroupParentSetter interface {
+	// SetParent puts cgroup under parent, or returns an error if the
+	// manager cannot place containers under it.
+	SetParent(cgroup *configs.Cgroup, parent string) error
+}
+
+// cgroupDriverFile is written in the state directory of a container with the
+// cgroup driver it was started with.
+const cgroupDriverFile = "cgroupdriver"
+
+// cgroupManagers are the registered cgroup managers by name.
+var cgroupManagers = make(map[string]CgroupManagerFactory)
+
+func init() {
+	RegisterCgroupManager("cgroupfs", cgroupfsManager{})
+	RegisterCgroupManager("systemd", systemdManager{})
+}
+
+// RegisterCgroupManager makes a cgroup manager available to the driver.
+// Managers must be registered before the driver is created.
+func RegisterCgroupManager(name string, f CgroupManagerFactory) error {
+	if _, exists := cgroupManagers[name]; exists {
+		return fmt.Errorf("Name already registered %s", name)
+	}
+	cgroupManagers[name] = f
+	return nil
+}
+
+// CgroupManagers returns the names of the registered cgroup managers that
+// are available on this host.
+func CgroupManagers() []string {
+	var names []string
+	for name, f := range cgroupManagers {
+		if f.Available() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+type cgroupfsManager struct{}
+
+func (cgroupfsManager) Available() bool {
+	return true
+}
+
+func (cgroupfsManager) Option() func(*libcontainer.LinuxFactory) error {
+	return libcontainer.Cgroupfs
+}
+
+// SetParent accepts the path of a cgroup, relative to the cgroup of the
+// daemon unless absolute, that is within the cgroup hierarchies.
+func (cgroupfsManager) SetParent(cgroup *configs.Cgroup, parent string) error {
+	for _, elem := range strings.Split(parent, "/") {
+		if elem == ".." {
+			return fmt.Errorf("Invalid cgroup parent %s, it cannot be outside of the cgroup hierarchies", parent)
+		}
+	}
+	cgroup.Parent = filepath.Clean(parent)
+	return nil
+}
+
+type systemdManager struct{}
+
+func (systemdManager) Available() bool {
+	return systemd.UseSystemd()
+}
+
+func (systemdManager) Option() func(*libcontainer.LinuxFactory) error {
+	return libcontainer.SystemdCgroups
+}
+
+// SetParent accepts a slice, such as web.slice or system-web.slice, or a
+// path of nested slices, such as system.slice/web.slice, which is the slice
+// system-web.slice in systemd's naming.
+func (systemdManager) SetParent(cgroup *configs.Cgroup, parent string) error {
+	var names []string
+	for _, elem := range strings.Split(strings.Trim(parent, "/"), "/") {
+		name := strings.TrimSuffix(elem, ".slice")
+		if name == elem || name == "" {
+			return fmt.Errorf("Invalid cgroup parent %s for the systemd cgroup driver, expected a slice such as web.slice or system.slice/web.slice", parent)
+		}
+		names = append(names, name)
+	}
+	// a slice is nested in the slices that its name is prefixed with
+	cgroup.Slice = strings.Join(names, "-") + ".slice"
+	return nil
+}
+
+// saveCgroupDriver records the cgroup driver the container is started with.
+func (d *driver) saveCgroupDriver(id, name string) error {
+	return ioutil.WriteFile(filepath.Join(d.root, id, cgroupDriverFile), []byte(name), 0600)
+}
+
+// loadContainer loads a container with the cgroup driver it was started
+// with, which is not the current one once native.cgroupdriver is reloaded or
+// changed across a restart of the daemon.  Containers started before the
+// driver was recorded are loaded with the current one.
+func (d *driver) loadContainer(id string) (libcontainer.Container, error) {
+	d.Lock()
+	name := d.options.cgroupDriver
+	d.Unlock()
+	data, err := ioutil.ReadFile(filepath.Join(d.root, id, cgroupDriverFile))
+	if err == nil {
+		name = string(data)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	d.Lock()
+	f, err := d.cgroupFactoryLocked(name)
+	d.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return f.Load(id)
+}
+
+// cgroupFactoryLocked returns the factory of the cgroup driver, creating it
+// the first time it is used.  The caller must hold the driver's lock.
+func (d *driver) cgroupFactoryLocked(name string) (libcontainer.Factory, error) {
+	if f, ok := d.factories[name]; ok {
+		return f, nil
+	}
+	m, ok := cgroupManagers[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown cgroup driver %s", name)
+	}
+	f, err := libcontainer.New(
+		d.root,
+		m.Option(),
+		libcontainer.InitPath(reexec.Self(), DriverName),
+	)
+	if err != nil {
+		return nil, err
+	}
+	d.factories[name] = f
+	return f, nil
+}
@@ -0,0 +1,360 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/pkg/reexec"
+)
+
+const (
+	// coreHelperPath is the path the kernel executes for core dumps when
+	// routing is enabled.  It is a symlink to the docker binary and, like
+	// /.dockerinit for lxc, the path itself is registered with reexec.
+	coreHelperPath = "/var/run/docker-coredump"
+	// coreHelperConfig holds the coreConfig for the helper.
+	coreHelperConfig = "/var/run/docker-coredump.json"
+	corePatternPath  = "/proc/sys/kernel/core_pattern"
+	coreUsesPidPath  = "/proc/sys/kernel/core_uses_pid"
+	// The fields of the dump are given in a single argument so that the
+	// specifiers unknown to the kernel, which it expands to nothing, do not
+	// shift them.  The executable name comes last as it may contain spaces.
+	coreHelperPattern = "|" + coreHelperPath + " %P:%p:%u:%g:%s:%t:%c:%d:%h %e"
+)
+
+var containerIDRegexp = regexp.MustCompile("^[a-f0-9]{64}$")
+
+func init() {
+	reexec.Register(coreHelperPath, coreDumpHelper)
+}
+
+// coreConfig is the configuration shared between the driver and the core
+// dump helper.
+type coreConfig struct {
+	// Root is the directory under which cores are written, in a directory
+	// per container.
+	Root string `json:"root"`
+	// SizeLimit is the maximum size in bytes of a single core file, bigger
+	// cores are truncated.
+	SizeLimit int64 `json:"size_limit"`
+	// HostPattern is the core_pattern of the host before the helper was
+	// installed.  The cores of host processes are handled by it and it is
+	// put back when routing stops.
+	HostPattern string `json:"host_pattern"`
+}
+
+// coreDump describes a core dump, as given to the helper by the kernel.
+type coreDump struct {
+	pid      string // in the initial pid namespace
+	localPid string // in the pid namespace of the process
+	uid      string
+	gid      string
+	signal   string
+	time     string
+	limit    string // RLIMIT_CORE of the process
+	dumpable string // 1 for a dumpable process, 2 for a setuid one with suid_dumpable=2
+	hostname string
+	comm     string
+}
+
+// coreDir returns the directory where the cores of the container are stored.
+func coreDir(root, id string) string {
+	return filepath.Join(root, "cores", id)
+}
+
+// setupCoreDumps installs the core dump helper as the kernel's core_pattern
+// so that core dumps of container processes end up in the container's core
+// directory.  The core_pattern of the host is saved for the cores of host
+// processes and put back by restoreCoreDumps.
+func setupCoreDumps(root string, sizeLimit int64, self string) error {
+	hostPattern, err := hostCorePattern()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(coreConfig{Root: root, SizeLimit: sizeLimit, HostPattern: hostPattern})
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(coreHelperConfig, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Remove(coreHelperPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Symlink(self, coreHelperPath); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(corePatternPath, []byte(coreHelperPattern), 0644)
+}
+
+// restoreCoreDumps puts back the core_pattern of the host and removes the
+// core dump helper.
+func restoreCoreDumps() error {
+	config, err := readCoreConfig()
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(corePatternPath, []byte(config.HostPattern), 0644); err != nil {
+		return err
+	}
+	if err := os.Remove(coreHelperPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Remove(coreHelperConfig)
+}
+
+// hostCorePattern returns the core_pattern of the host.  When the helper is
+// still installed, by a daemon that did not shut down cleanly or to change
+// the size limit, it is the one saved when the helper was installed.
+func hostCorePattern() (string, error) {
+	data, err := ioutil.ReadFile(corePatternPath)
+	if err != nil {
+		return "", err
+	}
+	pattern := strings.TrimSuffix(string(data), "\n")
+	if !strings.HasPrefix(pattern, "|"+coreHelperPath+" ") {
+		return pattern, nil
+	}
+	config, err := readCoreConfig()
+	if err != nil {
+		return "", fmt.Errorf("the core dump helper is installed but the host's core_pattern is lost: %v", err)
+	}
+	return config.HostPattern, nil
+}
+
+func readCoreConfig() (*coreConfig, error) {
+	data, err := ioutil.ReadFile(coreHelperConfig)
+	if err != nil {
+		return nil, err
+	}
+	var config coreConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// containerIDForPid returns the id of the container the process is running
+// in, or an empty string if the process does not belong to a container.
+func containerIDForPid(pid string) (string, error) {
+	f, err := os.Open(filepath.Join("/proc", pid, "cgroup"))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		parts := strings.SplitN(s.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if id := containerIDFromCgroup(parts[2]); id != "" {
+			return id, nil
+		}
+	}
+	return "", s.Err()
+}
+
+// containerIDFromCgroup extracts the container id from a cgroup path created
+// by either the cgroupfs (docker/<id>) or systemd (docker-<id>.scope) manager.
+func containerIDFromCgroup(path string) string {
+	name := filepath.Base(path)
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "docker-"), ".scope")
+	if containerIDRegexp.MatchString(name) {
+		return name
+	}
+	return ""
+}
+
+// coreDumpHelper is run by the kernel with the core on stdin and the fields
+// of coreHelperPattern as arguments.
+func coreDumpHelper() {
+	var fields []string
+	if len(os.Args) >= 3 {
+		fields = strings.Split(os.Args[1], ":")
+	}
+	if len(fields) != 9 {
+		writeError(fmt.Errorf("usage: %s <pid>:<local pid>:<uid>:<gid>:<signal>:<time>:<limit>:<dumpable>:<hostname> <comm>", coreHelperPath))
+	}
+	dump := coreDump{
+		pid:      fields[0],
+		localPid: fields[1],
+		uid:      fields[2],
+		gid:      fields[3],
+		signal:   fields[4],
+		time:     fields[5],
+		limit:    fields[6],
+		dumpable: fields[7],
+		hostname: fields[8],
+		comm:     strings.Join(os.Args[2:], " "),
+	}
+	// the kernel waits for the whole core to be consumed
+	defer io.Copy(ioutil.Discard, os.Stdin)
+
+	config, err := readCoreConfig()
+	if err != nil {
+		writeError(err)
+	}
+	id, err := containerIDForPid(dump.pid)
+	if err != nil {
+		writeError(err)
+	}
+	if id == "" {
+		if err := writeHostCore(config.HostPattern, dump, os.Stdin); err != nil {
+			writeError(err)
+		}
+		return
+	}
+
+	if err := writeContainerCore(coreDir(config.Root, id), dump, os.Stdin, config.SizeLimit); err != nil {
+		writeError(err)
+	}
+}
+
+// writeContainerCore writes the core of a container process to the core
+// directory of the container, truncated to the RLIMIT_CORE of the process
+// and to the size limit of the driver.
+func writeContainerCore(dir string, dump coreDump, core io.Reader, sizeLimit int64) error {
+	limit, ok := coreLimit(dump)
+	if !ok {
+		return nil
+	}
+	if sizeLimit > 0 && (limit == 0 || sizeLimit < limit) {
+		limit = sizeLimit
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("core.%s.%s.%s", dump.comm, dump.pid, dump.time))
+	return writeCore(path, core, limit)
+}
+
+// coreLimit returns the size a core may be written to the file system with,
+// zero if it is unlimited, from the RLIMIT_CORE of the process.  Like the
+// kernel, no core is written for a process with a zero RLIMIT_CORE.
+func coreLimit(dump coreDump) (int64, bool) {
+	limit, err := strconv.ParseUint(dump.limit, 10, 64)
+	if err != nil || limit == 0 {
+		return 0, false
+	}
+	if limit > math.MaxInt64 {
+		// RLIM_INFINITY
+		return 0, true
+	}
+	return int64(limit), true
+}
+
+// writeHostCore handles the core of a host process as the host's
+// core_pattern would: it is piped to the program of the pattern or written to
+// the file it names.  As the kernel does, the core of a process that is not
+// dumpable, such as a setuid one with suid_dumpable=2, is only written to an
+// absolute path and stays owned by root.
+func writeHostCore(pattern string, dump coreDump, core io.Reader) error {
+	if strings.HasPrefix(pattern, "|") {
+		var args []string
+		for _, arg := range strings.Fields(pattern[1:]) {
+			args = append(args, expandCorePattern(arg, dump))
+		}
+		if len(args) == 0 {
+			return nil
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = core
+		return cmd.Run()
+	}
+
+	limit, ok := coreLimit(dump)
+	if !ok || pattern == "" {
+		return nil
+	}
+	dumpable := dump.dumpable == "1"
+	path := expandCorePattern(pattern, dump)
+	if !strings.Contains(pattern, "%p") {
+		if data, err := ioutil.ReadFile(coreUsesPidPath); err == nil && strings.TrimSpace(string(data)) != "0" {
+			path += "." + dump.localPid
+		}
+	}
+	if !filepath.IsAbs(path) {
+		if !dumpable {
+			return fmt.Errorf("not writing the core of the non dumpable process %s to the relative path %s", dump.pid, path)
+		}
+		path = filepath.Join("/proc", dump.pid, "cwd", path)
+	}
+	if err := writeCore(path, core, limit); err != nil {
+		return err
+	}
+	if !dumpable {
+		return nil
+	}
+	uid, _ := strconv.Atoi(dump.uid)
+	gid, _ := strconv.Atoi(dump.gid)
+	return os.Chown(path, uid, gid)
+}
+
+// writeCore writes the core to a new file, truncated to the limit if it is
+// positive.
+func writeCore(path string, core io.Reader, limit int64) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if limit > 0 {
+		core = io.LimitReader(core, limit)
+	}
+	_, err = io.Copy(f, core)
+	return err
+}
+
+// expandCorePattern expands the specifiers of a core_pattern that the helper
+// knows of.  Like the kernel, it drops the other ones.
+func expandCorePattern(pattern string, dump coreDump) string {
+	var b []byte
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' || i == len(pattern)-1 {
+			b = append(b, pattern[i])
+			continue
+		}
+		i++
+		switch pattern[i] {
+		case '%':
+			b = append(b, '%')
+		case 'p':
+			b = append(b, dump.localPid...)
+		case 'P':
+			b = append(b, dump.pid...)
+		case 'u':
+			b = append(b, dump.uid...)
+		case 'g':
+			b = append(b, dump.gid...)
+		case 's':
+			b = append(b, dump.signal...)
+		case 't':
+			b = append(b, dump.time...)
+		case 'c':
+			b = append(b, dump.limit...)
+		case 'h':
+			b = append(b, dump.hostname...)
+		case 'e':
+			b = append(b, dump.comm...)
+		case 'E':
+			if exe, err := os.Readlink(filepath.Join("/proc", dump.pid, "exe")); err == nil {
+				b = append(b, strings.Replace(exe, "/", "!", -1)...)
+			}
+		}
+	}
+	return string(b)
+}
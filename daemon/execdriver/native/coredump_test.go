@@ -0,0 +1,127 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestExpandCorePattern(t *testing.T) {
+	dump := coreDump{pid: "100", localPid: "1", uid: "1000", gid: "1000", signal: "11", time: "1234", limit: "0", hostname: "host", comm: "sleep"}
+	tests := map[string]string{
+		"core":            "core",
+		"core.%p":         "core.1",
+		"/cores/%e.%P.%t": "/cores/sleep.100.1234",
+		"%u:%g:%s:%c:%h":  "1000:1000:11:0:host",
+		"100%%":           "100%",
+		"core.%z":         "core.",
+		"core%":           "core%",
+	}
+	for pattern, expected := range tests {
+		if path := expandCorePattern(pattern, dump); path != expected {
+			t.Fatalf("expected %q to expand to %q got %q", pattern, expected, path)
+		}
+	}
+}
+
+func TestWriteHostCore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-coredump-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dump := coreDump{pid: "100", localPid: "1", uid: strconv.Itoa(os.Getuid()), gid: strconv.Itoa(os.Getgid()), limit: "4", dumpable: "1", comm: "sleep"}
+	if err := writeHostCore(filepath.Join(dir, "core.%e.%p"), dump, strings.NewReader("core dump")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "core.sleep.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "core" {
+		t.Fatalf("expected the core truncated to its limit got %q", data)
+	}
+
+	// no core is written for a process with a zero RLIMIT_CORE
+	dump.limit = "0"
+	if err := writeHostCore(filepath.Join(dir, "nolimit.%p"), dump, strings.NewReader("core dump")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "nolimit.1")); !os.IsNotExist(err) {
+		t.Fatalf("expected no core for a zero limit got %v", err)
+	}
+}
+
+func TestWriteHostCoreNotDumpable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-coredump-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// a setuid process dumped with suid_dumpable=2
+	dump := coreDump{pid: strconv.Itoa(os.Getpid()), localPid: "1", uid: "12345", gid: "12345", limit: "100", dumpable: "2", comm: "passwd"}
+	if err := writeHostCore("core.%p", dump, strings.NewReader("core dump")); err == nil {
+		t.Fatal("expected the core of a non dumpable process not written to a relative path")
+	}
+
+	// the core is written to an absolute path but not given to the user
+	path := filepath.Join(dir, "core.1")
+	if err := writeHostCore(filepath.Join(dir, "core.%p"), dump, strings.NewReader("core dump")); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uid := fi.Sys().(*syscall.Stat_t).Uid; int(uid) != os.Getuid() {
+		t.Fatalf("expected the core of a non dumpable process kept by %d got %d", os.Getuid(), uid)
+	}
+}
+
+func TestWriteContainerCore(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-coredump-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	tests := []struct {
+		limit     string
+		sizeLimit int64
+		expected  string
+	}{
+		{"0", 0, ""},
+		{"4", 0, "core"},
+		{"4", 2, "co"},
+		{"18446744073709551615", 0, "core dump"},
+		{"18446744073709551615", 6, "core d"},
+	}
+	for i, test := range tests {
+		dir := filepath.Join(root, strconv.Itoa(i))
+		dump := coreDump{pid: "100", time: "1234", limit: test.limit, comm: "sleep"}
+		if err := writeContainerCore(dir, dump, strings.NewReader("core dump"), test.sizeLimit); err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, "core.sleep.100.1234"))
+		if test.expected == "" {
+			if !os.IsNotExist(err) {
+				t.Fatalf("expected no core for a zero RLIMIT_CORE got %q: %v", data, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != test.expected {
+			t.Fatalf("expected the core %q with RLIMIT_CORE %s and size limit %d got %q", test.expected, test.limit, test.sizeLimit, data)
+		}
+	}
+}
@@ -21,6 +21,10 @@ import (
 func (d *driver) createContainer(c *execdriver.Command) (*configs.Config, error) {
 	container := execdriver.InitContainer(c)
 
+	if err := d.setupCgroupParent(container, c); err != nil {
+		return nil, err
+	}
+
 	if err := d.createIpc(container, c); err != nil {
 		return nil, err
 	}
@@ -37,6 +41,13 @@ func (d *driver) createContainer(c *execdriver.Command) (*configs.Config, error)
 		return nil, err
 	}
 
+	if c.MaskPaths != nil {
+		container.MaskPaths = c.MaskPaths
+	}
+	if c.ReadonlyPaths != nil {
+		container.ReadonlyPaths = c.ReadonlyPaths
+	}
+
 	if c.ProcessConfig.Privileged {
 		// clear readonly for /sys
 		for i := range container.Mounts {
@@ -56,6 +67,9 @@ func (d *driver) createContainer(c *execdriver.Command) (*configs.Config, error)
 	}
 
 	if c.AppArmorProfile != "" {
+		if err := checkAppArmorProfile(c.AppArmorProfile); err != nil {
+			return nil, err
+		}
 		container.AppArmorProfile = c.AppArmorProfile
 	}
 
@@ -67,11 +81,76 @@ func (d *driver) createContainer(c *execdriver.Command) (*configs.Config, error)
 		return nil, err
 	}
 
+	if c.Init {
+		if err := d.setupInit(container, c); err != nil {
+			return nil, err
+		}
+	}
+
+	setRootPropagation(container, c)
 	d.setupLabels(container, c)
 	d.setupRlimits(container, c)
+	d.setupRandom(container)
 	return container, nil
 }
 
+// setupCgroupParent places the cgroup of the container under the parent of
+// the command as the cgroup manager of the driver expects it.
+func (d *driver) setupCgroupParent(container *configs.Config, c *execdriver.Command) error {
+	if c.CgroupParent == "" {
+		return nil
+	}
+	d.Lock()
+	cgroupDriver := d.options.cgroupDriver
+	d.Unlock()
+	s, ok := cgroupManagers[cgroupDriver].(CgroupParentSetter)
+	if !ok {
+		return nil
+	}
+	// the parent set by InitContainer is the default of the manager
+	container.Cgroups.Parent = "docker"
+	return s.SetParent(container.Cgroups, c.CgroupParent)
+}
+
+// setupInit mounts the init binary of the driver in the container for the
+// init shim to run from.
+func (d *driver) setupInit(container *configs.Config, c *execdriver.Command) error {
+	for _, m := range c.Mounts {
+		if m.Destination == "/dev" {
+			return fmt.Errorf("cannot run an init in a container mounting /dev")
+		}
+	}
+	container.Mounts = append(container.Mounts, &configs.Mount{
+		Source:      d.initPath,
+		Destination: initShimPath,
+		Device:      "bind",
+		Flags:       syscall.MS_BIND | syscall.MS_RDONLY,
+	})
+	return nil
+}
+
+// setupRandom creates the /dev/random node of the container with the device
+// numbers of /dev/urandom when the driver is configured with a non-blocking
+// random source, so that reads from /dev/random do not hang on hosts short of
+// entropy.
+func (d *driver) setupRandom(container *configs.Config) {
+	d.Lock()
+	nonBlockingRandom := d.options.nonBlockingRandom
+	d.Unlock()
+	if !nonBlockingRandom {
+		return
+	}
+	for i, device := range container.Devices {
+		if device.Path != "/dev/random" {
+			continue
+		}
+		// the devices are shared with other containers, don't modify them
+		urandom := *device
+		urandom.Major, urandom.Minor = 1, 9
+		container.Devices[i] = &urandom
+	}
+}
+
 func generateIfaceName() (string, error) {
 	for i := 0; i < 10; i++ {
 		name, err := utils.GenerateRandomName("veth", 7)
@@ -115,9 +194,14 @@ func (d *driver) createNetwork(container *configs.Config, c *execdriver.Command)
 	return nil
 }
 
+// createIpc sets up the IPC namespace of the container and its /dev/shm.
+// Containers sharing the IPC namespace of the host or of another container
+// share its /dev/shm too, so that POSIX shared memory is shared along with
+// System V IPC.
 func (d *driver) createIpc(container *configs.Config, c *execdriver.Command) error {
 	if c.Ipc.HostIpc {
 		container.Namespaces.Remove(configs.NEWIPC)
+		setupShm(container, "/dev/shm", 0)
 		return nil
 	}
 
@@ -135,17 +219,59 @@ func (d *driver) createIpc(container *configs.Config, c *execdriver.Command) err
 			return err
 		}
 		container.Namespaces.Add(configs.NEWIPC, state.NamespacePaths[configs.NEWIPC])
+		setupShm(container, fmt.Sprintf("/proc/%d/root/dev/shm", state.InitProcessPid), 0)
+		return nil
 	}
 
+	setupShm(container, "", c.Ipc.ShmSize)
 	return nil
 }
 
+// setupShm replaces the /dev/shm of the container by a bind mount of source
+// or, without a source, sizes its tmpfs when size is set.
+func setupShm(container *configs.Config, source string, size int64) {
+	for i, m := range container.Mounts {
+		if m.Destination != "/dev/shm" {
+			continue
+		}
+		switch {
+		case source != "":
+			container.Mounts[i] = &configs.Mount{
+				Source:      source,
+				Destination: "/dev/shm",
+				Device:      "bind",
+				Flags:       syscall.MS_BIND | syscall.MS_REC,
+			}
+		case size > 0:
+			shm := *m
+			shm.Data = fmt.Sprintf("mode=1777,size=%d", size)
+			container.Mounts[i] = &shm
+		}
+	}
+}
+
 func (d *driver) createPid(container *configs.Config, c *execdriver.Command) error {
 	if c.Pid.HostPid {
 		container.Namespaces.Remove(configs.NEWPID)
 		return nil
 	}
 
+	if c.Pid.ContainerID != "" {
+		d.Lock()
+		active := d.activeContainers[c.Pid.ContainerID]
+		d.Unlock()
+
+		if active == nil {
+			return fmt.Errorf("%s is not a valid running container to join", c.Pid.ContainerID)
+		}
+
+		state, err := active.State()
+		if err != nil {
+			return err
+		}
+		container.Namespaces.Add(configs.NEWPID, state.NamespacePaths[configs.NEWPID])
+	}
+
 	return nil
 }
 
@@ -159,6 +285,20 @@ func (d *driver) createUTS(container *configs.Config, c *execdriver.Command) err
 	return nil
 }
 
+// initArgs returns the arguments of the init of the container: libcontainer
+// does not set the domainname nor the real-time scheduling policy, the init
+// sets them before initializing the container or its exec'd processes.
+func initArgs(c *execdriver.Command) []string {
+	var args []string
+	if c.UTS != nil && !c.UTS.HostUTS && c.UTS.Domainname != "" {
+		args = append(args, domainnameArg+c.UTS.Domainname)
+	}
+	if c.RtPolicy != "" {
+		args = append(args, fmt.Sprintf("%s%s:%d", rtPolicyArg, c.RtPolicy, c.RtPriority))
+	}
+	return args
+}
+
 func (d *driver) setPrivileged(container *configs.Config) (err error) {
 	container.Capabilities = execdriver.GetAllCapabilities()
 	container.Cgroups.AllowAllDevices = true
@@ -200,6 +340,9 @@ func (d *driver) setupMounts(container *configs.Config, c *execdriver.Command) e
 	for _, m := range c.Mounts {
 		userMounts[m.Destination] = struct{}{}
 	}
+	for _, t := range c.Tmpfs {
+		userMounts[t.Destination] = struct{}{}
+	}
 
 	// Filter out mounts that are overriden by user supplied mounts
 	var defaultMounts []*configs.Mount
@@ -222,16 +365,46 @@ func (d *driver) setupMounts(container *configs.Config, c *execdriver.Command) e
 		if m.Slave {
 			flags |= syscall.MS_SLAVE
 		}
-		container.Mounts = append(container.Mounts, &configs.Mount{
+		mount := &configs.Mount{
 			Source:      m.Source,
 			Destination: m.Destination,
 			Device:      "bind",
 			Flags:       flags,
-		})
+		}
+		if m.Propagation != "" {
+			if err := setPropagation(mount, container.Rootfs, m.Propagation); err != nil {
+				return err
+			}
+		}
+		container.Mounts = append(container.Mounts, mount)
+	}
+	for _, t := range c.Tmpfs {
+		container.Mounts = append(container.Mounts, tmpfsMount(t))
 	}
 	return nil
 }
 
+// tmpfsMount returns the mount of a tmpfs, which is writable even when the
+// root filesystem is read only.  libcontainer keeps the permissions of a
+// directory of the image that the tmpfs is mounted on.
+func tmpfsMount(t execdriver.TmpfsMount) *configs.Mount {
+	mode := t.Mode
+	if mode == 0 {
+		mode = 01777
+	}
+	data := fmt.Sprintf("mode=%o", mode)
+	if t.Size > 0 {
+		data += fmt.Sprintf(",size=%d", t.Size)
+	}
+	return &configs.Mount{
+		Source:      "tmpfs",
+		Destination: t.Destination,
+		Device:      "tmpfs",
+		Flags:       syscall.MS_NOSUID | syscall.MS_NODEV,
+		Data:        data,
+	}
+}
+
 func (d *driver) setupLabels(container *configs.Config, c *execdriver.Command) {
 	container.ProcessLabel = c.ProcessLabel
 	container.MountLabel = c.MountLabel
@@ -0,0 +1,192 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/docker/libcontainer"
+	"github.com/syndtr/gocapability/capability"
+)
+
+// debugInitName is the reexec name of the init used for containers started
+// with DebugStart.  It logs the initialization of the container to the file
+// passed as its first argument.
+const debugInitName = DriverName + "-debug"
+
+// startLog records the phases of a container's startup with timestamps.
+// A nil *startLog discards everything so callers don't need to check
+// whether debugging is enabled.
+type startLog struct {
+	f *os.File
+}
+
+func openStartLog(path string) (*startLog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &startLog{f: f}, nil
+}
+
+func (l *startLog) Printf(format string, args ...interface{}) {
+	if l == nil {
+		return
+	}
+	fmt.Fprintf(l.f, "%s %s\n", time.Now().Format(time.RFC3339Nano), fmt.Sprintf(format, args...))
+}
+
+// Error logs err, including the stack trace when it comes from libcontainer.
+func (l *startLog) Error(err error) {
+	if l == nil {
+		return
+	}
+	l.Printf("error: %v", err)
+	if lerr, ok := err.(libcontainer.Error); ok {
+		lerr.Detail(l.f)
+	}
+}
+
+func (l *startLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.f.Close()
+}
+
+// debugLogPath returns the path of the startup log for the container.  It
+// lives outside of the container's state dir so that it survives the exit
+// of the container.
+func (d *driver) debugLogPath(id string) string {
+	return filepath.Join(d.root, "debug", id+".log")
+}
+
+// debugFactory returns a factory whose containers are initialized by the
+// debug init, logging to the startup log of the container.  The arguments
+// are passed to the init after the path of the log.
+func (d *driver) debugFactory(c *execdriver.Command, args ...string) (libcontainer.Factory, *startLog, error) {
+	path := d.debugLogPath(c.ID)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, nil, err
+	}
+	log, err := openStartLog(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := d.initFactory(c, append([]string{debugInitName, path}, args...)...)
+	if err != nil {
+		log.Close()
+		return nil, nil, err
+	}
+	return f, log, nil
+}
+
+// initFactory returns a factory for the container of the command whose
+// containers are initialized by the init registered under the first
+// argument, with the other arguments.
+func (d *driver) initFactory(c *execdriver.Command, args ...string) (libcontainer.Factory, error) {
+	d.Lock()
+	options := []func(*libcontainer.LinuxFactory) error{
+		d.options.cgroupManager(),
+		libcontainer.InitPath(reexec.Self(), args...),
+	}
+	d.Unlock()
+	if needsRtBudget(c) {
+		options = append(options, d.rtCgroups(c))
+	}
+	return libcontainer.New(d.root, options...)
+}
+
+// Debug describes a container as seen from inside its namespaces: the
+// effective libcontainer config, the mounts and the capabilities of its init
+// process.  For a container that is not running anymore it returns the
+// startup log, which has the same description of the init if the container
+// was started with DebugStart and failed to initialize.
+func (d *driver) Debug(id string) ([]byte, error) {
+	d.Lock()
+	active := d.activeContainers[id]
+	d.Unlock()
+	if active == nil {
+		data, err := ioutil.ReadFile(d.debugLogPath(id))
+		if os.IsNotExist(err) {
+			return nil, &execdriver.ErrContainerNotActive{ID: id}
+		}
+		return data, err
+	}
+	state, err := active.State()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	config, err := json.MarshalIndent(active.Config(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(&buf, "config:\n%s\n", config)
+	if err := writeProcessDebug(&buf, filepath.Join("/proc", strconv.Itoa(state.InitProcessPid))); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// capabilitySets are the capability sets of a process in /proc/<pid>/status.
+var capabilitySets = []string{"CapInh", "CapPrm", "CapEff", "CapBnd"}
+
+// writeProcessDebug writes the mounts, as seen in the mount namespace of the
+// process, and the capabilities of the process with the proc directory.
+func writeProcessDebug(w io.Writer, procDir string) error {
+	mounts, err := ioutil.ReadFile(filepath.Join(procDir, "mountinfo"))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "mounts:\n%s", mounts)
+
+	f, err := os.Open(filepath.Join(procDir, "status"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fmt.Fprintln(w, "capabilities:")
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		set := strings.TrimSuffix(fields[0], ":")
+		for _, name := range capabilitySets {
+			if set != name {
+				continue
+			}
+			mask, err := strconv.ParseUint(fields[1], 16, 64)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "%s\t%s\n", set, strings.Join(capabilityNames(mask), " "))
+		}
+	}
+	return s.Err()
+}
+
+// capabilityNames returns the names of the capabilities in the mask.
+func capabilityNames(mask uint64) []string {
+	var names []string
+	for _, c := range capability.List() {
+		if mask&(1<<uint(c)) != 0 {
+			names = append(names, strings.ToUpper(c.String()))
+		}
+	}
+	return names
+}
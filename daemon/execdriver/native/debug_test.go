@@ -0,0 +1,30 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCapabilityNames(t *testing.T) {
+	// CAP_CHOWN, CAP_KILL and CAP_NET_BIND_SERVICE
+	names := capabilityNames(1<<0 | 1<<5 | 1<<10)
+	if got := strings.Join(names, " "); got != "CHOWN KILL NET_BIND_SERVICE" {
+		t.Fatalf("unexpected capabilities %q", got)
+	}
+}
+
+func TestWriteProcessDebug(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeProcessDebug(&buf, "/proc/self"); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, expected := range []string{"mounts:\n", "capabilities:\n", "CapEff\t", "CapBnd\t"} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("expected %q in\n%s", expected, out)
+		}
+	}
+}
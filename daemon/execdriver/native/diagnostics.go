@@ -0,0 +1,90 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Diagnostics collects the libcontainer state, a snapshot of the cgroup
+// files, the resource limits, the startup log and the list of core dumps of
+// the container.
+func (d *driver) Diagnostics(id string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	for name, path := range map[string]string{
+		"state.json":      filepath.Join(d.containerDir(id), "state.json"),
+		"debug-start.log": d.debugLogPath(id),
+	} {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		files[name] = data
+	}
+
+	d.Lock()
+	active := d.activeContainers[id]
+	d.Unlock()
+	if active != nil {
+		state, err := active.State()
+		if err != nil {
+			return nil, err
+		}
+		for subsystem, path := range state.CgroupPaths {
+			snapshotCgroup(files, subsystem, path)
+		}
+		if limits, err := (&info{ID: id, driver: d}).Rlimits(); err == nil {
+			var buf bytes.Buffer
+			for _, l := range limits {
+				fmt.Fprintln(&buf, l)
+			}
+			files["limits.txt"] = buf.Bytes()
+		}
+		if stdio, err := (&info{ID: id, driver: d}).StdioStats(); err == nil {
+			if data, err := json.MarshalIndent(stdio, "", "  "); err == nil {
+				files["stdio.json"] = data
+			}
+		}
+		if stats, err := d.Stats(id); err == nil {
+			if data, err := json.MarshalIndent(stats, "", "  "); err == nil {
+				files["stats.json"] = data
+			}
+		}
+	}
+
+	if cores, err := ioutil.ReadDir(coreDir(d.root, id)); err == nil {
+		var buf bytes.Buffer
+		for _, core := range cores {
+			fmt.Fprintf(&buf, "%s\t%d\t%s\n", core.Name(), core.Size(), core.ModTime())
+		}
+		files["cores.txt"] = buf.Bytes()
+	}
+	return files, nil
+}
+
+// snapshotCgroup adds the readable files of a cgroup directory to files.
+func snapshotCgroup(files map[string][]byte, subsystem, path string) {
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if !e.Mode().IsRegular() {
+			continue
+		}
+		// some cgroup files are write only, skip the ones we can't read
+		data, err := ioutil.ReadFile(filepath.Join(path, e.Name()))
+		if err != nil {
+			continue
+		}
+		files[filepath.Join("cgroups", subsystem, e.Name())] = data
+	}
+}
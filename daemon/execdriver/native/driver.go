@@ -5,6 +5,7 @@ package native
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -15,13 +16,11 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/daemon/execdriver"
-	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/pkg/reexec"
 	sysinfo "github.com/docker/docker/pkg/system"
 	"github.com/docker/docker/pkg/term"
 	"github.com/docker/libcontainer"
 	"github.com/docker/libcontainer/apparmor"
-	"github.com/docker/libcontainer/cgroups/systemd"
 	"github.com/docker/libcontainer/configs"
 	"github.com/docker/libcontainer/system"
 	"github.com/docker/libcontainer/utils"
@@ -38,77 +37,97 @@ type driver struct {
 	activeContainers map[string]libcontainer.Container
 	machineMemory    int64
 	factory          libcontainer.Factory
+	factories        map[string]libcontainer.Factory // by cgroup driver, to load containers
+	options          *driverOptions
+	exitRequests     map[string]exitRequest
+	oomSubscribers   map[string][]chan execdriver.OOMEvent
+	statsStreams     map[string]map[time.Duration]*statsStream
+	restored         map[string]bool     // running containers not reattached yet
+	rtBudgets        map[string]rtBudget // by cpu cgroup
+	memoryHigh       map[string]chan struct{}
+	metrics          *driverMetrics
+	consoles         map[string]*TtyConsole
+	winsizes         map[string]*term.Winsize // requested before the console exists
+	stdio            map[string]*stdio
 	sync.Mutex
 }
 
+// exitRequest records the last request made through the driver to make a
+// container exit, so that the exit can be attributed to it.
+type exitRequest struct {
+	initiator string
+	signal    syscall.Signal
+}
+
+func init() {
+	execdriver.Register(DriverName, func(config *execdriver.DriverConfig) (execdriver.Driver, error) {
+		return NewDriver(filepath.Join(config.Root, "execdriver", DriverName), config.InitPath, config.Options)
+	})
+}
+
 func NewDriver(root, initPath string, options []string) (*driver, error) {
 	meminfo, err := sysinfo.ReadMemInfo()
 	if err != nil {
 		return nil, err
 	}
 
-	if err := sysinfo.MkdirAll(root, 0700); err != nil {
+	opts, err := parseOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sysinfo.MkdirAll(root, opts.rootMode); err != nil {
+		return nil, err
+	}
+	// MkdirAll leaves the mode of an existing root as is
+	if err := os.Chmod(root, opts.rootMode); err != nil {
 		return nil, err
 	}
 	// native driver root is at docker_root/execdriver/native. Put apparmor at docker_root
 	if err := apparmor.InstallDefaultProfile(); err != nil {
 		return nil, err
 	}
-
-	// choose cgroup manager
-	// this makes sure there are no breaking changes to people
-	// who upgrade from versions without native.cgroupdriver opt
-	cgm := libcontainer.Cgroupfs
-	if systemd.UseSystemd() {
-		cgm = libcontainer.SystemdCgroups
+	if err := setupPropagationHelper(reexec.Self()); err != nil {
+		return nil, err
 	}
-
-	// parse the options
-	for _, option := range options {
-		key, val, err := parsers.ParseKeyValueOpt(option)
-		if err != nil {
+	if opts.coreDumpSize >= 0 {
+		if err := setupCoreDumps(root, opts.coreDumpSize, reexec.Self()); err != nil {
 			return nil, err
 		}
-		key = strings.ToLower(key)
-		switch key {
-		case "native.cgroupdriver":
-			// override the default if they set options
-			switch val {
-			case "systemd":
-				if systemd.UseSystemd() {
-					cgm = libcontainer.SystemdCgroups
-				} else {
-					// warn them that they chose the wrong driver
-					logrus.Warn("You cannot use systemd as native.cgroupdriver, using cgroupfs instead")
-				}
-			case "cgroupfs":
-				cgm = libcontainer.Cgroupfs
-			default:
-				return nil, fmt.Errorf("Unknown native.cgroupdriver given %q. try cgroupfs or systemd", val)
-			}
-		default:
-			return nil, fmt.Errorf("Unknown option %s\n", key)
-		}
 	}
 
-	logrus.Debugf("Using %v as native.cgroupdriver", cgm)
+	logrus.Debugf("Using %v as native.cgroupdriver", opts.cgroupDriver)
 
 	f, err := libcontainer.New(
 		root,
-		cgm,
+		opts.cgroupManager(),
 		libcontainer.InitPath(reexec.Self(), DriverName),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	return &driver{
+	d := &driver{
 		root:             root,
 		initPath:         initPath,
 		activeContainers: make(map[string]libcontainer.Container),
 		machineMemory:    meminfo.MemTotal,
 		factory:          f,
-	}, nil
+		factories:        map[string]libcontainer.Factory{opts.cgroupDriver: f},
+		options:          opts,
+		exitRequests:     make(map[string]exitRequest),
+		oomSubscribers:   make(map[string][]chan execdriver.OOMEvent),
+		statsStreams:     make(map[string]map[time.Duration]*statsStream),
+		restored:         make(map[string]bool),
+		rtBudgets:        make(map[string]rtBudget),
+		memoryHigh:       make(map[string]chan struct{}),
+		metrics:          newDriverMetrics(),
+		consoles:         make(map[string]*TtyConsole),
+		winsizes:         make(map[string]*term.Winsize),
+		stdio:            make(map[string]*stdio),
+	}
+	d.restoreContainers()
+	return d, nil
 }
 
 type execOutput struct {
@@ -117,9 +136,38 @@ type execOutput struct {
 }
 
 func (d *driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, startCallback execdriver.StartCallback) (execdriver.ExitStatus, error) {
+	d.Lock()
+	factory := d.factory
+	cgroupDriver := d.options.cgroupDriver
+	rootMode := d.options.rootMode
+	stdio := newStdio(d.options.stdioBuffer, d.options.stdioRate)
+	d.Unlock()
+	// failure is the step being run until the container has started
+	started, failure := time.Now(), "config"
+	defer func() {
+		if failure != "" {
+			d.metrics.failedStarts.Inc(failure)
+		}
+	}()
+	var log *startLog
+	if c.DebugStart {
+		var err error
+		if factory, log, err = d.debugFactory(c, initArgs(c)...); err != nil {
+			return execdriver.ExitStatus{ExitCode: -1}, err
+		}
+		defer log.Close()
+	} else if args := initArgs(c); args != nil {
+		var err error
+		if factory, err = d.initFactory(c, append([]string{DriverName}, args...)...); err != nil {
+			return execdriver.ExitStatus{ExitCode: -1}, err
+		}
+	}
+
 	// take the Command and populate the libcontainer.Config from it
+	log.Printf("creating container configuration")
 	container, err := d.createContainer(c)
 	if err != nil {
+		log.Error(err)
 		return execdriver.ExitStatus{ExitCode: -1}, err
 	}
 
@@ -129,45 +177,117 @@ func (d *driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, startCallba
 		Cwd:  c.WorkingDir,
 		User: c.ProcessConfig.User,
 	}
+	if c.Init {
+		p.Args = append([]string{initShimPath, "--"}, p.Args...)
+	}
 
-	if err := setupPipes(container, &c.ProcessConfig, p, pipes); err != nil {
+	if err := setupPipes(container, &c.ProcessConfig, p, stdio.wrap(pipes)); err != nil {
+		log.Error(err)
 		return execdriver.ExitStatus{ExitCode: -1}, err
 	}
 
-	cont, err := d.factory.Create(c.ID, container)
+	log.Printf("creating container")
+	failure = "create"
+	cont, err := factory.Create(c.ID, container)
 	if err != nil {
+		log.Error(err)
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	if err := d.saveCgroupDriver(c.ID, cgroupDriver); err != nil {
+		log.Error(err)
+		cont.Destroy()
 		return execdriver.ExitStatus{ExitCode: -1}, err
 	}
 	d.Lock()
 	d.activeContainers[c.ID] = cont
+	d.stdio[c.ID] = stdio
+	if tty, ok := c.ProcessConfig.Terminal.(*TtyConsole); ok {
+		d.consoles[c.ID] = tty
+		if ws, ok := d.winsizes[c.ID]; ok {
+			if err := tty.Resize(int(ws.Height), int(ws.Width)); err != nil {
+				logrus.Debugf("Failed to resize the console of %s: %v", c.ID, err)
+			}
+			delete(d.winsizes, c.ID)
+		}
+	}
 	d.Unlock()
 	defer func() {
 		cont.Destroy()
 		d.cleanContainer(c.ID)
 	}()
+	// libcontainer creates the state directory only accessible by root
+	if err := os.Chmod(d.containerDir(c.ID), rootMode); err != nil {
+		log.Error(err)
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
 
+	log.Printf("starting init process %v", p.Args)
+	failure = "start"
 	if err := cont.Start(p); err != nil {
+		log.Error(err)
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+
+	failure = "setup"
+	if err := applyOomScoreAdj(c, p); err != nil {
+		log.Error(err)
+		p.Signal(os.Kill)
+		p.Wait()
 		return execdriver.ExitStatus{ExitCode: -1}, err
 	}
 
+	if err := setBlkioLimits(cont, c.Resources); err != nil {
+		log.Error(err)
+		p.Signal(os.Kill)
+		p.Wait()
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+
+	pid, err := p.Pid()
+	if err != nil {
+		p.Signal(os.Kill)
+		p.Wait()
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	netDirs, err := setupNetClass(c, cont, pid)
+	defer removeNetClass(netDirs)
+	if err != nil {
+		log.Error(err)
+		p.Signal(os.Kill)
+		p.Wait()
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+
+	failure = ""
+	d.metrics.startLatency.Observe(time.Since(started).Seconds())
 	if startCallback != nil {
-		pid, err := p.Pid()
-		if err != nil {
-			p.Signal(os.Kill)
-			p.Wait()
-			return execdriver.ExitStatus{ExitCode: -1}, err
-		}
 		startCallback(&c.ProcessConfig, pid)
 	}
+	log.Printf("container started")
+
+	// the cgroup is gone once the container exits so look it up now in case
+	// we need to find its OOM report
+	var memoryCgroup string
+	if state, err := cont.State(); err == nil {
+		if memoryCgroup, err = memoryCgroupName(state.CgroupPaths); err != nil {
+			logrus.Debugf("Failed to find memory cgroup of %s: %v", c.ID, err)
+		}
+	}
 
-	oom := notifyOnOOM(cont)
+	memoryHighDone := make(chan struct{})
+	if c.Resources != nil && c.Resources.MemoryHigh > 0 {
+		go d.throttleMemoryHigh(c, cont, memoryHighDone)
+	}
+
+	oom := d.watchOOM(c.ID, cont, memoryCgroup)
 	waitF := p.Wait
-	if nss := cont.Config().Namespaces; !nss.Contains(configs.NEWPID) {
+	if !ownsPidNamespace(cont.Config()) {
 		// we need such hack for tracking processes with inherited fds,
 		// because cmd.Wait() waiting for all streams to be copied
 		waitF = waitInPIDHost(p, cont)
 	}
 	ps, err := waitF()
+	close(memoryHighDone)
 	if err != nil {
 		execErr, ok := err.(*exec.ExitError)
 		if !ok {
@@ -176,8 +296,27 @@ func (d *driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, startCallba
 		ps = execErr.ProcessState
 	}
 	cont.Destroy()
-	_, oomKill := <-oom
-	return execdriver.ExitStatus{ExitCode: utils.ExitStatus(ps.Sys().(syscall.WaitStatus)), OOMKilled: oomKill}, nil
+	oomKill := <-oom
+	if oomKill {
+		d.metrics.oomKills.Inc("")
+	}
+	ws := ps.Sys().(syscall.WaitStatus)
+	exitCode := utils.ExitStatus(ws)
+	log.Printf("container exited with code %d", exitCode)
+	exitStatus := execdriver.ExitStatus{
+		ExitCode:  exitCode,
+		OOMKilled: oomKill,
+		Initiator: d.exitInitiator(c.ID, ws, oomKill),
+	}
+	if ws.Signaled() {
+		exitStatus.Signal = int(ws.Signal())
+	}
+	if oomKill && memoryCgroup != "" {
+		if exitStatus.OOMReport, err = readOOMReport(memoryCgroup); err != nil {
+			logrus.Warnf("Failed to read the OOM report of %s: %v", c.ID, err)
+		}
+	}
+	return exitStatus, nil
 }
 
 // notifyOnOOM returns a channel that signals if the container received an OOM notification
@@ -194,6 +333,21 @@ func notifyOnOOM(container libcontainer.Container) <-chan struct{} {
 	return oom
 }
 
+// exitInitiator returns what caused the container to exit with the given
+// status.
+func (d *driver) exitInitiator(id string, ws syscall.WaitStatus, oomKill bool) string {
+	if oomKill {
+		return execdriver.InitiatorOOM
+	}
+	d.Lock()
+	req, ok := d.exitRequests[id]
+	d.Unlock()
+	if ok && ws.Signaled() && ws.Signal() == req.signal {
+		return req.initiator
+	}
+	return execdriver.InitiatorWorkload
+}
+
 func killCgroupProcs(c libcontainer.Container) {
 	var procs []*os.Process
 	if err := c.Pause(); err != nil {
@@ -222,6 +376,19 @@ func killCgroupProcs(c libcontainer.Container) {
 	}
 }
 
+// ownsPidNamespace returns whether the init of the container is the init of
+// a pid namespace of its own, whose processes the kernel kills when the init
+// exits.  Containers in the pid namespace of the host or of another container
+// leave their processes behind.
+func ownsPidNamespace(config configs.Config) bool {
+	for _, ns := range config.Namespaces {
+		if ns.Type == configs.NEWPID {
+			return ns.Path == ""
+		}
+	}
+	return false
+}
+
 func waitInPIDHost(p *libcontainer.Process, c libcontainer.Container) func() (*os.ProcessState, error) {
 	return func() (*os.ProcessState, error) {
 		pid, err := p.Pid()
@@ -247,36 +414,81 @@ func waitInPIDHost(p *libcontainer.Process, c libcontainer.Container) func() (*o
 func (d *driver) Kill(c *execdriver.Command, sig int) error {
 	d.Lock()
 	active := d.activeContainers[c.ID]
+	if active != nil {
+		d.exitRequests[c.ID] = exitRequest{execdriver.InitiatorKill, syscall.Signal(sig)}
+	}
 	d.Unlock()
 	if active == nil {
-		return fmt.Errorf("active container for %s does not exist", c.ID)
+		return &execdriver.ErrContainerNotActive{ID: c.ID}
 	}
 	state, err := active.State()
 	if err != nil {
 		return err
 	}
-	return syscall.Kill(state.InitProcessPid, syscall.Signal(sig))
+	return signalInit(c.ID, active, state.InitProcessPid, syscall.Signal(sig))
+}
+
+// signalInit sends the signal to the init process of the container.  The
+// processes of a paused container cannot handle signals, so a paused
+// container is resumed for the signal to take effect.  SIGKILL is sent before
+// resuming so that the processes do not run again.
+func signalInit(id string, active libcontainer.Container, pid int, sig syscall.Signal) error {
+	status, err := active.Status()
+	if err != nil {
+		return err
+	}
+	if status != libcontainer.Paused {
+		return syscall.Kill(pid, sig)
+	}
+	if sig == syscall.SIGKILL {
+		if err := syscall.Kill(pid, sig); err != nil {
+			return err
+		}
+	}
+	if err := active.Resume(); err != nil {
+		return &execdriver.ErrCgroupFailure{ID: id, Err: err}
+	}
+	if sig != syscall.SIGKILL {
+		return syscall.Kill(pid, sig)
+	}
+	return nil
 }
 
 func (d *driver) Pause(c *execdriver.Command) error {
+	d.Lock()
 	active := d.activeContainers[c.ID]
+	d.Unlock()
 	if active == nil {
-		return fmt.Errorf("active container for %s does not exist", c.ID)
+		return &execdriver.ErrContainerNotActive{ID: c.ID}
+	}
+	if status, err := active.Status(); err == nil && status == libcontainer.Paused {
+		return &execdriver.ErrContainerPaused{ID: c.ID}
 	}
-	return active.Pause()
+	if err := active.Pause(); err != nil {
+		return &execdriver.ErrCgroupFailure{ID: c.ID, Err: err}
+	}
+	return nil
 }
 
 func (d *driver) Unpause(c *execdriver.Command) error {
+	d.Lock()
 	active := d.activeContainers[c.ID]
+	d.Unlock()
 	if active == nil {
-		return fmt.Errorf("active container for %s does not exist", c.ID)
+		return &execdriver.ErrContainerNotActive{ID: c.ID}
+	}
+	if err := active.Resume(); err != nil {
+		return &execdriver.ErrCgroupFailure{ID: c.ID, Err: err}
 	}
-	return active.Resume()
+	return nil
 }
 
 func (d *driver) Terminate(c *execdriver.Command) error {
 	defer d.cleanContainer(c.ID)
-	container, err := d.factory.Load(c.ID)
+	d.Lock()
+	d.exitRequests[c.ID] = exitRequest{execdriver.InitiatorTerminate, syscall.SIGKILL}
+	d.Unlock()
+	container, err := d.loadContainer(c.ID)
 	if err != nil {
 		return err
 	}
@@ -304,6 +516,56 @@ func (d *driver) Info(id string) execdriver.Info {
 	}
 }
 
+func (d *driver) DriverCapabilities() execdriver.DriverCapabilities {
+	caps := execdriver.DriverCapabilities{
+		Version: execdriver.APIVersion,
+		Features: []execdriver.Feature{
+			execdriver.FeatureExec,
+			execdriver.FeaturePause,
+			execdriver.FeatureStats,
+			execdriver.FeatureDiagnostics,
+			execdriver.FeatureMemoryHigh,
+			execdriver.FeatureUpdate,
+			execdriver.FeatureAddDevice,
+			execdriver.FeatureBlkioLimits,
+			execdriver.FeatureNetClass,
+			execdriver.FeatureSignal,
+			execdriver.FeatureKillPaused,
+			execdriver.FeatureMetrics,
+			execdriver.FeatureTmpfs,
+			execdriver.FeatureShmSize,
+			execdriver.FeatureInit,
+		},
+	}
+	d.Lock()
+	if d.options.cpuRtRequired {
+		caps.Features = append(caps.Features, execdriver.FeatureRealtime)
+	}
+	d.Unlock()
+	return caps
+}
+
+// Status reports the cgroup manager in use and the available ones.
+func (d *driver) Status() [][2]string {
+	d.Lock()
+	cgroupDriver := d.options.cgroupDriver
+	d.Unlock()
+	return [][2]string{
+		{"Cgroup Driver", cgroupDriver},
+		{"Cgroup Drivers", strings.Join(CgroupManagers(), ", ")},
+	}
+}
+
+// Shutdown puts back the host's core_pattern when core dumps are routed.
+func (d *driver) Shutdown() error {
+	d.Lock()
+	defer d.Unlock()
+	if d.options.coreDumpSize < 0 {
+		return nil
+	}
+	return restoreCoreDumps()
+}
+
 func (d *driver) Name() string {
 	return fmt.Sprintf("%s-%s", DriverName, Version)
 }
@@ -314,31 +576,72 @@ func (d *driver) GetPidsForContainer(id string) ([]int, error) {
 	d.Unlock()
 
 	if active == nil {
-		return nil, fmt.Errorf("active container for %s does not exist", id)
+		return nil, &execdriver.ErrContainerNotActive{ID: id}
+	}
+	pids, err := active.Processes()
+	if err != nil {
+		return nil, &execdriver.ErrCgroupFailure{ID: id, Err: err}
 	}
-	return active.Processes()
+	return pids, nil
+}
+
+// Resize sets the size of the console of the container.  A size requested
+// before the console is created is applied as soon as it is.
+func (d *driver) Resize(id string, h, w int) error {
+	d.Lock()
+	defer d.Unlock()
+	if tty, ok := d.consoles[id]; ok {
+		return tty.Resize(h, w)
+	}
+	d.winsizes[id] = &term.Winsize{Height: uint16(h), Width: uint16(w)}
+	return nil
 }
 
 func (d *driver) cleanContainer(id string) error {
 	d.Lock()
 	delete(d.activeContainers, id)
+	delete(d.exitRequests, id)
+	delete(d.restored, id)
+	delete(d.consoles, id)
+	delete(d.winsizes, id)
+	delete(d.stdio, id)
+	for _, ch := range d.oomSubscribers[id] {
+		close(ch)
+	}
+	delete(d.oomSubscribers, id)
+	d.closeStatsStreams(id)
+	d.releaseRtBudgets(id)
 	d.Unlock()
-	return os.RemoveAll(filepath.Join(d.root, id))
+	return os.RemoveAll(d.containerDir(id))
 }
 
-func (d *driver) createContainerRoot(id string) error {
-	return os.MkdirAll(filepath.Join(d.root, id), 0655)
+// containerDir returns the directory where libcontainer keeps the state of
+// the container.
+func (d *driver) containerDir(id string) string {
+	return filepath.Join(d.root, id)
 }
 
 func (d *driver) Clean(id string) error {
-	return os.RemoveAll(filepath.Join(d.root, id))
+	if err := os.RemoveAll(d.debugLogPath(id)); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(coreDir(d.root, id)); err != nil {
+		return err
+	}
+	return os.RemoveAll(d.containerDir(id))
 }
 
 func (d *driver) Stats(id string) (*execdriver.ResourceStats, error) {
+	d.Lock()
 	c := d.activeContainers[id]
+	d.Unlock()
 	if c == nil {
 		return nil, execdriver.ErrNotRunning
 	}
+	return d.containerStats(id, c)
+}
+
+func (d *driver) containerStats(id string, c libcontainer.Container) (*execdriver.ResourceStats, error) {
 	now := time.Now()
 	stats, err := c.Stats()
 	if err != nil {
@@ -350,11 +653,71 @@ func (d *driver) Stats(id string) (*execdriver.ResourceStats, error) {
 	if memoryLimit == 0 {
 		memoryLimit = d.machineMemory
 	}
-	return &execdriver.ResourceStats{
-		Stats:       stats,
-		Read:        now,
-		MemoryLimit: memoryLimit,
-	}, nil
+	rs := &execdriver.ResourceStats{
+		Stats:          stats,
+		Read:           now,
+		MemoryLimit:    memoryLimit,
+		OomKillDisable: c.Config().Cgroups.OomKillDisable,
+	}
+	if state, err := c.State(); err == nil {
+		if rs.ExecStats, err = execStats(state.CgroupPaths); err != nil {
+			logrus.Debugf("Failed to read exec stats of %s: %v", id, err)
+		}
+		rs.OomScoreAdj = readOomScoreAdj(state.InitProcessPid)
+		// the interfaces are set up outside of libcontainer, read them from
+		// the network namespace unless it is the host's
+		if nss := c.Config().Namespaces; len(stats.Interfaces) == 0 && nss.Contains(configs.NEWNET) {
+			if stats.Interfaces, err = readNetDev(state.InitProcessPid); err != nil {
+				logrus.Debugf("Failed to read network stats of %s: %v", id, err)
+			}
+		}
+		if path, ok := state.CgroupPaths["blkio"]; ok {
+			if rs.BlkioThrottleServiceBytes, err = readBlkioThrottleStats(filepath.Join(path, "blkio.throttle.io_service_bytes")); err != nil {
+				logrus.Debugf("Failed to read blkio throttle stats of %s: %v", id, err)
+			}
+			if rs.BlkioThrottleServiced, err = readBlkioThrottleStats(filepath.Join(path, "blkio.throttle.io_serviced")); err != nil {
+				logrus.Debugf("Failed to read blkio throttle stats of %s: %v", id, err)
+			}
+		}
+	}
+	return rs, nil
+}
+
+// resettableCounters are the memory cgroup files that the kernel allows to be
+// reset by writing 0 to them.
+var resettableCounters = []string{
+	"memory.max_usage_in_bytes",
+	"memory.failcnt",
+	"memory.memsw.max_usage_in_bytes",
+	"memory.memsw.failcnt",
+	"memory.kmem.max_usage_in_bytes",
+	"memory.kmem.failcnt",
+}
+
+// ResetStats zeroes the resettable cgroup counters (max usage, failcnt) of a
+// running container so that peak usage can be measured from this point on.
+func (d *driver) ResetStats(id string) error {
+	d.Lock()
+	active := d.activeContainers[id]
+	d.Unlock()
+	if active == nil {
+		return execdriver.ErrNotRunning
+	}
+	state, err := active.State()
+	if err != nil {
+		return err
+	}
+	path, ok := state.CgroupPaths["memory"]
+	if !ok {
+		return fmt.Errorf("memory cgroup is not available for %s", id)
+	}
+	for _, file := range resettableCounters {
+		// swap and kernel memory accounting are optional in the kernel
+		if err := ioutil.WriteFile(filepath.Join(path, file), []byte("0"), 0700); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
 }
 
 type TtyConsole struct {
@@ -408,6 +771,19 @@ func (t *TtyConsole) Close() error {
 	return t.console.Close()
 }
 
+// copyStdin copies the attached stdin to the container's stdin.  When the
+// attached stdin ends, which with StdinOnce is when its client closes it, the
+// container's stdin is closed so that it reads EOF while its stdout and stderr
+// keep streaming.  When the container closes its stdin first the attached
+// stdin is closed so that the client does not block writing to it.
+func copyStdin(w io.WriteCloser, stdin io.ReadCloser) {
+	if _, err := io.Copy(w, stdin); err != nil {
+		logrus.Debugf("Stopped copying stdin: %v", err)
+	}
+	w.Close()
+	stdin.Close()
+}
+
 func setupPipes(container *configs.Config, processConfig *execdriver.ProcessConfig, p *libcontainer.Process, pipes *execdriver.Pipes) error {
 	var term execdriver.Terminal
 	var err error
@@ -425,15 +801,12 @@ func setupPipes(container *configs.Config, processConfig *execdriver.ProcessConf
 	} else {
 		p.Stdout = pipes.Stdout
 		p.Stderr = pipes.Stderr
-		r, w, err := os.Pipe()
-		if err != nil {
-			return err
-		}
 		if pipes.Stdin != nil {
-			go func() {
-				io.Copy(w, pipes.Stdin)
-				w.Close()
-			}()
+			r, w, err := os.Pipe()
+			if err != nil {
+				return err
+			}
+			go copyStdin(w, pipes.Stdin)
 			p.Stdin = r
 		}
 		term = &execdriver.StdConsole{}
@@ -0,0 +1,412 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/daemon/execdriver/native/template"
+	"github.com/docker/libcontainer/configs"
+)
+
+func TestCopyStdinClosesContainerStdin(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	copyStdin(w, ioutil.NopCloser(strings.NewReader("input")))
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "input" {
+		t.Fatalf("expected the container to read %q, got %q", "input", data)
+	}
+}
+
+func TestCopyStdinClosesAttachedStdin(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the container closed its stdin
+	r.Close()
+	stdin, stdinPipe := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		copyStdin(w, stdin)
+		close(done)
+	}()
+	if _, err := stdinPipe.Write([]byte("input")); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if _, err := stdinPipe.Write([]byte("more")); err != io.ErrClosedPipe {
+		t.Fatalf("expected writing to the attached stdin to fail with %v, got %v", io.ErrClosedPipe, err)
+	}
+}
+
+func TestTmpfsMount(t *testing.T) {
+	for _, tc := range []struct {
+		tmpfs execdriver.TmpfsMount
+		data  string
+	}{
+		{execdriver.TmpfsMount{Destination: "/tmp"}, "mode=1777"},
+		{execdriver.TmpfsMount{Destination: "/run", Size: 1024, Mode: 0755}, "mode=755,size=1024"},
+	} {
+		m := tmpfsMount(tc.tmpfs)
+		if m.Destination != tc.tmpfs.Destination || m.Device != "tmpfs" || m.Data != tc.data {
+			t.Fatalf("expected a tmpfs on %s with %q, got %+v", tc.tmpfs.Destination, tc.data, m)
+		}
+	}
+}
+
+func TestSetupMountsPropagation(t *testing.T) {
+	d := &driver{}
+	container := &configs.Config{Rootfs: "/var/lib/docker/rootfs"}
+	c := &execdriver.Command{Mounts: []execdriver.Mount{
+		{Source: "/var/lib/plugin", Destination: "/plugin", Writable: true, Propagation: "rshared"},
+		{Source: "/data", Destination: "/data"},
+	}}
+	if err := d.setupMounts(container, c); err != nil {
+		t.Fatal(err)
+	}
+	cmds := container.Mounts[0].PostmountCmds
+	if len(cmds) != 1 || cmds[0].Path != propagationHelperPath {
+		t.Fatalf("expected the propagation helper to run after mounting /plugin, got %v", cmds)
+	}
+	flags := strconv.Itoa(syscall.MS_SHARED | syscall.MS_REC)
+	if args := cmds[0].Args; len(args) != 3 || args[0] != container.Rootfs || args[1] != "/plugin" || args[2] != flags {
+		t.Fatalf("expected the helper to make /plugin rshared, got %v", args)
+	}
+	if cmds := container.Mounts[1].PostmountCmds; cmds != nil {
+		t.Fatalf("expected the mount of /data to keep the default propagation, got %v", cmds)
+	}
+
+	c.Mounts[0].Propagation = "unbindable"
+	if err := d.setupMounts(&configs.Config{}, c); err == nil {
+		t.Fatal("expected an error for an invalid propagation")
+	}
+}
+
+func TestSetRootPropagation(t *testing.T) {
+	newContainer := func() *configs.Config {
+		return &configs.Config{
+			Rootfs: "/var/lib/docker/rootfs",
+			Mounts: []*configs.Mount{{Destination: "/proc"}, {Destination: "/plugin"}, {Destination: "/data"}},
+		}
+	}
+	c := &execdriver.Command{Mounts: []execdriver.Mount{
+		{Source: "/var/lib/plugin", Destination: "/plugin", Propagation: "shared"},
+		{Source: "/data", Destination: "/data", Propagation: "slave"},
+	}}
+	container := newContainer()
+	setRootPropagation(container, c)
+	if container.RootPropagation != syscall.MS_SHARED|syscall.MS_REC {
+		t.Fatalf("expected the rootfs rshared for a shared mount, got %#x", container.RootPropagation)
+	}
+	if cmds := container.Mounts[0].PremountCmds; len(cmds) != 1 || len(cmds[0].Args) != 2 || cmds[0].Args[0] != privateRootfsArg {
+		t.Fatalf("expected the rootfs made private before the first mount, got %v", cmds)
+	}
+	if cmds := container.Mounts[2].PostmountCmds; len(cmds) != 1 || len(cmds[0].Args) != 2 || cmds[0].Args[0] != slaveHostArg {
+		t.Fatalf("expected the host mounts made slaves after the last mount, got %v", cmds)
+	}
+
+	c.Mounts[0].Propagation = "private"
+	container = newContainer()
+	setRootPropagation(container, c)
+	if container.RootPropagation != 0 || container.Mounts[0].PremountCmds != nil {
+		t.Fatalf("expected the default propagation of the rootfs without a shared mount, got %#x", container.RootPropagation)
+	}
+}
+
+func TestSetupShm(t *testing.T) {
+	shm := func(container *configs.Config) *configs.Mount {
+		for _, m := range container.Mounts {
+			if m.Destination == "/dev/shm" {
+				return m
+			}
+		}
+		t.Fatal("no /dev/shm in the container")
+		return nil
+	}
+
+	container := template.New()
+	setupShm(container, "", 128)
+	if m := shm(container); m.Device != "tmpfs" || m.Data != "mode=1777,size=128" {
+		t.Fatalf("expected a tmpfs of 128 bytes, got %+v", m)
+	}
+
+	container = template.New()
+	setupShm(container, "/dev/shm", 0)
+	if m := shm(container); m.Device != "bind" || m.Source != "/dev/shm" {
+		t.Fatalf("expected a bind mount of the host's /dev/shm, got %+v", m)
+	}
+}
+
+func TestOwnsPidNamespace(t *testing.T) {
+	container := template.New()
+	if !ownsPidNamespace(*container) {
+		t.Fatal("expected the container to own its PID namespace")
+	}
+	container.Namespaces.Add(configs.NEWPID, "/proc/1/ns/pid")
+	if ownsPidNamespace(*container) {
+		t.Fatal("expected a container joining a PID namespace not to own it")
+	}
+	container.Namespaces.Remove(configs.NEWPID)
+	if ownsPidNamespace(*container) {
+		t.Fatal("expected a container in the host PID namespace not to own it")
+	}
+}
+
+func TestInitArgsDomainname(t *testing.T) {
+	c := &execdriver.Command{UTS: &execdriver.UTS{Domainname: "example.com"}}
+	if args := initArgs(c); len(args) != 1 || args[0] != "--domainname=example.com" {
+		t.Fatalf("expected the init to set the domainname, got %v", args)
+	}
+	c.UTS.HostUTS = true
+	if args := initArgs(c); args != nil {
+		t.Fatalf("expected no domainname in the host's UTS namespace, got %v", args)
+	}
+	if args := initArgs(&execdriver.Command{UTS: &execdriver.UTS{}}); args != nil {
+		t.Fatalf("expected no arguments without a domainname, got %v", args)
+	}
+}
+
+func TestInitArgsRtPolicy(t *testing.T) {
+	c := &execdriver.Command{RtPolicy: "fifo", RtPriority: 10}
+	if args := initArgs(c); len(args) != 1 || args[0] != "--rtpolicy=fifo:10" {
+		t.Fatalf("expected the init to set the real-time policy, got %v", args)
+	}
+}
+
+func TestSetRtRuntimeTwoContainers(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-rt-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	parent := filepath.Join(root, "docker")
+	a := filepath.Join(parent, "a")
+	b := filepath.Join(parent, "b")
+	for _, dir := range []string{a, b} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, dir := range []string{parent, a, b} {
+		if err := ioutil.WriteFile(filepath.Join(dir, "cpu.rt_runtime_us"), []byte("0"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := &driver{rtBudgets: make(map[string]rtBudget)}
+	readRuntime := func(dir string) int64 {
+		runtime, err := readRtRuntime(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return runtime
+	}
+
+	if err := d.setRtRuntime("a", root, a, 100000); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.setRtRuntime("b", root, b, 50000); err != nil {
+		t.Fatal(err)
+	}
+	if runtime := readRuntime(parent); runtime != 150000 {
+		t.Fatalf("expected the runtime of %s raised to the sum of its children, got %d", parent, runtime)
+	}
+	if runtime := readRuntime(a); runtime != 100000 {
+		t.Fatalf("expected the runtime of %s set to 100000, got %d", a, runtime)
+	}
+
+	for _, dir := range []string{a, b} {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d.releaseRtBudgets("a")
+	if runtime := readRuntime(parent); runtime != 50000 {
+		t.Fatalf("expected the runtime of %s lowered to 50000, got %d", parent, runtime)
+	}
+	d.releaseRtBudgets("b")
+	if runtime := readRuntime(parent); runtime != 0 || len(d.rtBudgets) != 0 {
+		t.Fatalf("expected the runtime of %s put back to 0, got %d", parent, runtime)
+	}
+}
+
+func TestSetRtRuntimeSpareBudget(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-rt-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	parent := filepath.Join(root, "docker")
+	child := filepath.Join(parent, "web")
+	if err := os.MkdirAll(child, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for dir, runtime := range map[string]string{parent: "950000", child: "0"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, "cpu.rt_runtime_us"), []byte(runtime), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	raised, err := setRtRuntime(root, child, 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raised) != 0 {
+		t.Fatalf("expected no ancestor raised, got %v", raised)
+	}
+	if runtime, err := readRtRuntime(parent); err != nil || runtime != 950000 {
+		t.Fatalf("expected the runtime of %s kept, got %d (%v)", parent, runtime, err)
+	}
+}
+
+func TestCreateUTS(t *testing.T) {
+	d := &driver{}
+	container := template.New()
+	container.Hostname = "web"
+	if err := d.createUTS(container, &execdriver.Command{UTS: &execdriver.UTS{Domainname: "example.com"}}); err != nil {
+		t.Fatal(err)
+	}
+	if container.Hostname != "web" || !container.Namespaces.Contains(configs.NEWUTS) {
+		t.Fatalf("expected web in a UTS namespace, got %s", container.Hostname)
+	}
+
+	if err := d.createUTS(container, &execdriver.Command{UTS: &execdriver.UTS{HostUTS: true}}); err != nil {
+		t.Fatal(err)
+	}
+	if container.Hostname != "" || container.Namespaces.Contains(configs.NEWUTS) {
+		t.Fatalf("expected the host's UTS, got %s", container.Hostname)
+	}
+}
+
+func TestReapChildren(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "exit 3")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if status, exited := reapChildren(cmd.Process.Pid); exited {
+			if status != 3 {
+				t.Fatalf("expected the exit status 3, got %d", status)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("the child was not reaped")
+}
+
+func TestSetupInit(t *testing.T) {
+	d := &driver{initPath: "/usr/lib/docker/dockerinit"}
+	container := template.New()
+	if err := d.setupInit(container, &execdriver.Command{Init: true}); err != nil {
+		t.Fatal(err)
+	}
+	m := container.Mounts[len(container.Mounts)-1]
+	if m.Source != d.initPath || m.Destination != initShimPath || m.Flags&syscall.MS_RDONLY == 0 {
+		t.Fatalf("expected the init to be mounted read only at %s, got %+v", initShimPath, m)
+	}
+
+	c := &execdriver.Command{Init: true, Mounts: []execdriver.Mount{{Source: "/dev", Destination: "/dev"}}}
+	if err := d.setupInit(template.New(), c); err == nil {
+		t.Fatal("expected an error for an init with /dev mounted")
+	}
+}
+
+func TestWaitForCgroups(t *testing.T) {
+	fds, err := syscall.Socketpair(syscall.AF_LOCAL, syscall.SOCK_STREAM, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer syscall.Close(fds[0])
+	defer syscall.Close(fds[1])
+
+	done := make(chan error)
+	go func() {
+		done <- waitForCgroups(fds[0])
+	}()
+	select {
+	case <-done:
+		t.Fatal("expected to wait for the config")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _, err := syscall.Write(fds[1], []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	// the config is left for libcontainer
+	buf := make([]byte, 2)
+	if n, err := syscall.Read(fds[0], buf); err != nil || string(buf[:n]) != "{}" {
+		t.Fatalf("expected the config to be left in the pipe got %q: %v", buf[:n], err)
+	}
+}
+
+func TestCreateCgroupDirsCoMounted(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-exec-cgroup-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	memory, cpu := filepath.Join(root, "memory"), filepath.Join(root, "cpu,cpuacct")
+	for _, dir := range []string{memory, cpu} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(memory, "memory.use_hierarchy"), []byte("1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// cpu and cpuacct are links to the directory they are mounted on
+	for _, link := range []string{"cpu", "cpuacct"} {
+		if err := os.Symlink(cpu, filepath.Join(root, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	paths := map[string]string{
+		"memory":  memory,
+		"cpu":     filepath.Join(root, "cpu"),
+		"cpuacct": filepath.Join(root, "cpuacct"),
+	}
+	r := &execdriver.Resources{Memory: 1 << 20, CpuShares: 512, CpuQuota: 50000}
+	dirs, procs, err := createCgroupDirs("web", paths, "exec-test", r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	closeFiles(procs)
+	if len(dirs) != 2 || len(procs) != 2 {
+		t.Fatalf("expected a cgroup for memory and one for cpu and cpuacct, got %v", dirs)
+	}
+	for file, expected := range map[string]string{
+		filepath.Join(memory, "exec-test", "memory.limit_in_bytes"): "1048576",
+		filepath.Join(cpu, "exec-test", "cpu.shares"):               "512",
+		filepath.Join(cpu, "exec-test", "cpu.cfs_quota_us"):         "50000",
+	} {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected {
+			t.Fatalf("expected %s in %s got %s", expected, file, data)
+		}
+	}
+}
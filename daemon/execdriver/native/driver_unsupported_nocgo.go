@@ -8,6 +8,12 @@ import (
 	"github.com/docker/docker/daemon/execdriver"
 )
 
+func init() {
+	execdriver.Register("native", func(config *execdriver.DriverConfig) (execdriver.Driver, error) {
+		return NewDriver(config.Root, config.InitPath)
+	})
+}
+
 func NewDriver(root, initPath string) (execdriver.Driver, error) {
 	return nil, fmt.Errorf("native driver not supported on non-linux")
 }
@@ -3,7 +3,6 @@
 package native
 
 import (
-	"fmt"
 	"os"
 	"os/exec"
 	"syscall"
@@ -15,9 +14,14 @@ import (
 )
 
 func (d *driver) Exec(c *execdriver.Command, processConfig *execdriver.ProcessConfig, pipes *execdriver.Pipes, startCallback execdriver.StartCallback) (int, error) {
+	d.Lock()
 	active := d.activeContainers[c.ID]
+	d.Unlock()
 	if active == nil {
-		return -1, fmt.Errorf("No active container exists with ID %s", c.ID)
+		return -1, &execdriver.ErrContainerNotActive{ID: c.ID}
+	}
+	if status, err := active.Status(); err == nil && status == libcontainer.Paused {
+		return -1, &execdriver.ErrContainerPaused{ID: c.ID}
 	}
 
 	p := &libcontainer.Process{
@@ -31,22 +35,51 @@ func (d *driver) Exec(c *execdriver.Command, processConfig *execdriver.ProcessCo
 		p.Capabilities = execdriver.GetAllCapabilities()
 	}
 
+	d.Lock()
+	stdio, ok := d.stdio[c.ID]
+	if !ok {
+		// restored containers have no stdio of their own
+		stdio = newStdio(d.options.stdioBuffer, d.options.stdioRate)
+	}
+	d.Unlock()
 	config := active.Config()
-	if err := setupPipes(&config, processConfig, p, pipes); err != nil {
+	if err := setupPipes(&config, processConfig, p, stdio.wrap(pipes)); err != nil {
+		return -1, err
+	}
+
+	dirs, procs, err := createExecCgroups(active, processConfig.Resources)
+	defer removeExecCgroups(dirs)
+	if err != nil {
+		closeFiles(procs)
+		return -1, err
+	}
+	p.ExtraFiles = procs
+	err = active.Start(p)
+	// the init of the process has its own copies
+	closeFiles(procs)
+	if err != nil {
+		return -1, err
+	}
+
+	if err := applyOomScoreAdj(c, p); err != nil {
+		p.Signal(os.Kill)
+		p.Wait()
 		return -1, err
 	}
 
-	if err := active.Start(p); err != nil {
+	pid, err := p.Pid()
+	if err != nil {
+		p.Signal(os.Kill)
+		p.Wait()
+		return -1, err
+	}
+	if err := joinNetClass(c, active, pid); err != nil {
+		p.Signal(os.Kill)
+		p.Wait()
 		return -1, err
 	}
 
 	if startCallback != nil {
-		pid, err := p.Pid()
-		if err != nil {
-			p.Signal(os.Kill)
-			p.Wait()
-			return -1, err
-		}
 		startCallback(&c.ProcessConfig, pid)
 	}
 
@@ -60,3 +93,9 @@ func (d *driver) Exec(c *execdriver.Command, processConfig *execdriver.ProcessCo
 	}
 	return utils.ExitStatus(ps.Sys().(syscall.WaitStatus)), nil
 }
+
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		f.Close()
+	}
+}
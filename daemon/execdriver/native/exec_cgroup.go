@@ -0,0 +1,194 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/libcontainer"
+)
+
+// execCgroupName returns a new name for the cgroups, below the container's,
+// of an exec'd process.
+func execCgroupName() string {
+	return "exec-" + stringid.TruncateID(stringid.GenerateRandomID())
+}
+
+// execCgroupSubsystems are the subsystems in which an exec'd process with
+// resources gets cgroups of its own.
+var execCgroupSubsystems = []string{"memory", "cpu", "cpuacct"}
+
+// createExecCgroups creates cgroups of its own below the container's cgroups
+// for a process exec'd with resources, limiting it so that it cannot starve
+// the container's workload.  Its memory and cpu usage is then accounted
+// separately.  It returns the created cgroup directories and their opened
+// cgroup.procs files, which are passed to the init of the process for it to
+// join the cgroups before the process is run.
+func createExecCgroups(container libcontainer.Container, r *execdriver.Resources) ([]string, []*os.File, error) {
+	if r == nil {
+		return nil, nil, nil
+	}
+	state, err := container.State()
+	if err != nil {
+		return nil, nil, err
+	}
+	return createCgroupDirs(container.ID(), state.CgroupPaths, execCgroupName(), r)
+}
+
+// createCgroupDirs creates the cgroup name below the cgroups of the container
+// at paths and applies the resources to it.  Subsystems mounted together,
+// usually cpu and cpuacct, share a directory, which is created once and
+// holds the settings of all of them.
+func createCgroupDirs(id string, paths map[string]string, name string, r *execdriver.Resources) ([]string, []*os.File, error) {
+	settings := map[string]map[string]int64{
+		"memory":  {"memory.limit_in_bytes": r.Memory},
+		"cpu":     {"cpu.shares": r.CpuShares, "cpu.cfs_quota_us": r.CpuQuota},
+		"cpuacct": {},
+	}
+	var (
+		parents []string
+		files   = make(map[string]map[string]int64) // by parent directory
+	)
+	for _, subsystem := range execCgroupSubsystems {
+		parent, ok := paths[subsystem]
+		if !ok {
+			return nil, nil, fmt.Errorf("%s cgroup is not available for %s", subsystem, id)
+		}
+		if subsystem == "memory" {
+			// without hierarchy the process would escape the container's limit
+			if hierarchy, err := readCgroupUint(filepath.Join(parent, "memory.use_hierarchy")); err != nil || hierarchy != 1 {
+				if r.Memory > 0 {
+					return nil, nil, fmt.Errorf("memory cgroup of %s is not hierarchical", id)
+				}
+				continue
+			}
+		}
+		if resolved, err := filepath.EvalSymlinks(parent); err == nil {
+			parent = resolved
+		}
+		if _, ok := files[parent]; !ok {
+			parents = append(parents, parent)
+			files[parent] = make(map[string]int64)
+		}
+		for file, value := range settings[subsystem] {
+			files[parent][file] = value
+		}
+	}
+
+	var (
+		dirs  []string
+		procs []*os.File
+	)
+	for _, parent := range parents {
+		dir := filepath.Join(parent, name)
+		if err := os.Mkdir(dir, 0755); err != nil {
+			return dirs, procs, err
+		}
+		dirs = append(dirs, dir)
+		for file, value := range files[parent] {
+			if value <= 0 {
+				continue
+			}
+			if err := ioutil.WriteFile(filepath.Join(dir, file), []byte(strconv.FormatInt(value, 10)), 0644); err != nil {
+				return dirs, procs, err
+			}
+		}
+		f, err := os.OpenFile(filepath.Join(dir, "cgroup.procs"), os.O_WRONLY|os.O_CREATE, 0644)
+		if err != nil {
+			return dirs, procs, err
+		}
+		procs = append(procs, f)
+	}
+	return dirs, procs, nil
+}
+
+// removeExecCgroups removes the cgroups of an exec'd process once it exited.
+// The processes it left behind are moved to the container's cgroups first,
+// a cgroup with processes cannot be removed.
+func removeExecCgroups(dirs []string) {
+	for _, dir := range dirs {
+		if err := moveCgroupProcs(dir, filepath.Dir(dir)); err != nil {
+			logrus.Warnf("Failed to move the processes of exec cgroup %s: %v", dir, err)
+		}
+		if err := os.Remove(dir); err != nil && !os.IsNotExist(err) {
+			logrus.Warnf("Failed to remove exec cgroup %s: %v", dir, err)
+		}
+	}
+}
+
+// moveCgroupProcs moves the processes of a cgroup to another one.
+func moveCgroupProcs(from, to string) error {
+	data, err := ioutil.ReadFile(filepath.Join(from, "cgroup.procs"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, pid := range strings.Fields(string(data)) {
+		if err := ioutil.WriteFile(filepath.Join(to, "cgroup.procs"), []byte(pid), 0644); err != nil {
+			// the process may have exited in the meantime
+			if perr, ok := err.(*os.PathError); ok && perr.Err == syscall.ESRCH {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// execStats sums the usage of the cgroups of the processes exec'd in the
+// container.
+func execStats(cgroupPaths map[string]string) (*execdriver.ExecStats, error) {
+	stats := &execdriver.ExecStats{}
+	if path, ok := cgroupPaths["cpuacct"]; ok {
+		dirs, err := filepath.Glob(filepath.Join(path, "exec-*"))
+		if err != nil {
+			return nil, err
+		}
+		stats.Sessions = len(dirs)
+		for _, dir := range dirs {
+			usage, err := readCgroupUint(filepath.Join(dir, "cpuacct.usage"))
+			if err != nil {
+				return nil, err
+			}
+			stats.CpuUsage += usage
+		}
+	}
+	if path, ok := cgroupPaths["memory"]; ok {
+		dirs, err := filepath.Glob(filepath.Join(path, "exec-*"))
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range dirs {
+			usage, err := readCgroupUint(filepath.Join(dir, "memory.usage_in_bytes"))
+			if err != nil {
+				return nil, err
+			}
+			stats.MemoryUsage += usage
+		}
+	}
+	return stats, nil
+}
+
+// readCgroupUint reads a cgroup file holding a single unsigned integer.  An
+// exec cgroup removed in the meantime reads as zero.
+func readCgroupUint(path string) (uint64, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
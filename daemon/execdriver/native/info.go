@@ -2,6 +2,15 @@
 
 package native
 
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/pkg/ulimit"
+)
+
 type info struct {
 	ID     string
 	driver *driver
@@ -11,6 +20,50 @@ type info struct {
 // pid file for a container.  If the file exists then the
 // container is currently running
 func (i *info) IsRunning() bool {
+	i.driver.Lock()
 	_, ok := i.driver.activeContainers[i.ID]
+	i.driver.Unlock()
 	return ok
 }
+
+// StdioStats returns the bytes copied between the stdio of the container and
+// its clients, including the ones of exec commands.
+func (i *info) StdioStats() (execdriver.StdioStats, error) {
+	i.driver.Lock()
+	s := i.driver.stdio[i.ID]
+	i.driver.Unlock()
+	if s == nil {
+		return execdriver.StdioStats{}, &execdriver.ErrContainerNotActive{ID: i.ID}
+	}
+	return s.stats(), nil
+}
+
+// Rlimits returns the resource limits of the init process of the container.
+// Unlimited resources are reported as -1.
+func (i *info) Rlimits() ([]*ulimit.Ulimit, error) {
+	i.driver.Lock()
+	active := i.driver.activeContainers[i.ID]
+	i.driver.Unlock()
+	if active == nil {
+		return nil, &execdriver.ErrContainerNotActive{ID: i.ID}
+	}
+	state, err := active.State()
+	if err != nil {
+		return nil, err
+	}
+	var limits []*ulimit.Ulimit
+	for _, name := range ulimit.Names() {
+		u := &ulimit.Ulimit{Name: name}
+		rl, err := u.GetRlimit()
+		if err != nil {
+			return nil, err
+		}
+		var lim syscall.Rlimit
+		if _, _, errno := syscall.RawSyscall6(syscall.SYS_PRLIMIT64, uintptr(state.InitProcessPid), uintptr(rl.Type), 0, uintptr(unsafe.Pointer(&lim)), 0, 0); errno != 0 {
+			return nil, fmt.Errorf("get %s limit of %d: %v", name, state.InitProcessPid, errno)
+		}
+		u.Soft, u.Hard = int64(lim.Cur), int64(lim.Max)
+		limits = append(limits, u)
+	}
+	return limits, nil
+}
@@ -6,13 +6,35 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/pkg/reexec"
 	"github.com/docker/libcontainer"
 )
 
+const (
+	// domainnameArg prefixes the argument giving the init the domainname
+	// of the container.
+	domainnameArg = "--domainname="
+	// rtPolicyArg prefixes the argument giving the init the real-time
+	// scheduling policy and priority of the container's processes, as
+	// <policy>:<priority>.
+	rtPolicyArg = "--rtpolicy="
+)
+
+// real-time scheduling policies from <sched.h>
+var rtPolicies = map[string]int{
+	"fifo": 1, // SCHED_FIFO
+	"rr":   2, // SCHED_RR
+}
+
 func init() {
 	reexec.Register(DriverName, initializer)
+	reexec.Register(debugInitName, debugInitializer)
 }
 
 func fatal(err error) {
@@ -28,11 +50,54 @@ func fatal(err error) {
 func initializer() {
 	runtime.GOMAXPROCS(1)
 	runtime.LockOSThread()
+	if err := setupProcess(os.Args[1:]); err != nil {
+		fatal(err)
+	}
+	factory, err := libcontainer.New("")
+	if err != nil {
+		fatal(err)
+	}
+	if err := factory.StartInitialization(); err != nil {
+		fatal(err)
+	}
+
+	panic("unreachable")
+}
+
+// debugInitializer is the initializer for containers started with
+// DebugStart.  It logs the initialization to the file given as argument.
+func debugInitializer() {
+	runtime.GOMAXPROCS(1)
+	runtime.LockOSThread()
+	if len(os.Args) < 2 {
+		fatal(fmt.Errorf("no startup log given to %s", debugInitName))
+	}
+	log, err := openStartLog(os.Args[1])
+	if err != nil {
+		fatal(err)
+	}
+	// send the logs of the libcontainer init to the startup log as well
+	logrus.SetOutput(log.f)
+	logrus.SetLevel(logrus.DebugLevel)
+
+	log.Printf("init: started as pid %d with uid %d", os.Getpid(), os.Getuid())
+	if err := setupProcess(os.Args[2:]); err != nil {
+		log.Error(err)
+		fatal(err)
+	}
 	factory, err := libcontainer.New("")
 	if err != nil {
+		log.Error(err)
 		fatal(err)
 	}
+	log.Printf("init: starting initialization")
 	if err := factory.StartInitialization(); err != nil {
+		log.Error(err)
+		// we are in the namespaces of the container, describe what the
+		// initialization left behind
+		if err := writeProcessDebug(log.f, "/proc/self"); err != nil {
+			log.Printf("init: cannot describe the process: %v", err)
+		}
 		fatal(err)
 	}
 
@@ -43,3 +108,117 @@ func writeError(err error) {
 	fmt.Fprint(os.Stderr, err)
 	os.Exit(1)
 }
+
+// setupProcess applies the settings given in the arguments of the init, and
+// the ones passed to the processes exec'd in the container, before
+// libcontainer initializes the process.
+func setupProcess(args []string) error {
+	if err := setDomainname(args); err != nil {
+		return err
+	}
+	if err := joinExecCgroups(); err != nil {
+		return err
+	}
+	return setRtPolicy(args)
+}
+
+// setDomainname sets the domainname given in the arguments of the init.  The
+// init of the container is started in its UTS namespace, the processes
+// joining the container keep the domainname it has.
+func setDomainname(args []string) error {
+	// exec'd processes run the init of the container as well
+	if os.Getenv("_LIBCONTAINER_INITTYPE") != "standard" {
+		return nil
+	}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, domainnameArg) {
+			return syscall.Setdomainname([]byte(strings.TrimPrefix(arg, domainnameArg)))
+		}
+	}
+	return nil
+}
+
+// joinExecCgroups moves an exec'd process into its exec cgroups, whose
+// cgroup.procs files are passed to it before the init pipe of libcontainer.
+// The files are not passed on to the process.
+func joinExecCgroups() error {
+	if os.Getenv("_LIBCONTAINER_INITTYPE") != "setns" {
+		return nil
+	}
+	pipe, err := strconv.Atoi(os.Getenv("_LIBCONTAINER_INITPIPE"))
+	if err != nil {
+		return err
+	}
+	if pipe == 3 {
+		return nil
+	}
+	// libcontainer moves the process into the cgroups of the container
+	// after starting it, the exec cgroups are joined once it is done
+	if err := waitForCgroups(pipe); err != nil {
+		return err
+	}
+	for fd := 3; fd < pipe; fd++ {
+		// 0 is the writing process
+		if _, err := syscall.Write(fd, []byte("0")); err != nil {
+			return fmt.Errorf("cannot join the exec cgroup: %v", err)
+		}
+		syscall.CloseOnExec(fd)
+	}
+	return nil
+}
+
+// waitForCgroups waits for libcontainer to have placed the process in the
+// cgroups of the container.  It sends the config of the process on the init
+// pipe once it is done, which is peeked at to leave it to libcontainer.
+func waitForCgroups(pipe int) error {
+	buf := make([]byte, 1)
+	for {
+		_, _, err := syscall.Recvfrom(pipe, buf, syscall.MSG_PEEK)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
+
+// setRtPolicy sets the real-time scheduling policy given in the arguments of
+// the init, for the container's init and the processes exec'd in it, once
+// the process is in the cgroups holding the container's real-time budget.
+func setRtPolicy(args []string) error {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, rtPolicyArg) {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimPrefix(arg, rtPolicyArg), ":", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid real-time scheduling policy %q", arg)
+		}
+		priority, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return err
+		}
+		pipe, err := strconv.Atoi(os.Getenv("_LIBCONTAINER_INITPIPE"))
+		if err != nil {
+			return err
+		}
+		if err := waitForCgroups(pipe); err != nil {
+			return err
+		}
+		// the process is exec'd by the current thread
+		return setScheduler(0, parts[0], priority)
+	}
+	return nil
+}
+
+// setScheduler sets the real-time scheduling policy and priority of the
+// thread.  Processes forked or exec'd afterwards inherit them.
+func setScheduler(pid int, policy string, priority int) error {
+	p, ok := rtPolicies[policy]
+	if !ok {
+		return fmt.Errorf("invalid real-time scheduling policy %q", policy)
+	}
+	param := struct{ priority int32 }{int32(priority)}
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETSCHEDULER, uintptr(pid), uintptr(p), uintptr(unsafe.Pointer(&param))); errno != 0 {
+		return fmt.Errorf("set scheduler of %d to %s: %v", pid, policy, errno)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// +build linux
+
+package native
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/docker/libcontainer/utils"
+)
+
+// initShimPath is where the driver mounts its init binary in containers
+// started with Init, the init running as their pid 1 is registered under it.
+const initShimPath = "/dev/init"
+
+// prSetChildSubreaper is PR_SET_CHILD_SUBREAPER, missing from syscall.
+const prSetChildSubreaper = 36
+
+func init() {
+	reexec.Register(initShimPath, initShim)
+}
+
+// initShim is the pid 1 of containers started with Init.  It runs the
+// command it is given after "--", forwards it the signals it receives and
+// reaps the zombies left in the container until the command exits, then
+// exits with the status of the command.
+func initShim() {
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		fatal(fmt.Errorf("no command given to %s", initShimPath))
+	}
+	// adopt the orphans of the command when it is not in a pid namespace of
+	// its own either
+	if _, _, err := syscall.RawSyscall(syscall.SYS_PRCTL, prSetChildSubreaper, 1, 0); err != 0 {
+		fatal(err)
+	}
+	signals := make(chan os.Signal, 32)
+	signal.Notify(signals)
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		fatal(err)
+	}
+	// the command is waited for by reapChildren along with the zombies, it
+	// may have exited before the signals were handled
+	if status, exited := reapChildren(cmd.Process.Pid); exited {
+		os.Exit(status)
+	}
+	for sig := range signals {
+		if sig != syscall.SIGCHLD {
+			cmd.Process.Signal(sig)
+			continue
+		}
+		if status, exited := reapChildren(cmd.Process.Pid); exited {
+			os.Exit(status)
+		}
+	}
+}
+
+// reapChildren waits for the children that have exited and returns the exit
+// status of pid if it is one of them.
+func reapChildren(pid int) (int, bool) {
+	for {
+		var ws syscall.WaitStatus
+		wpid, err := syscall.Wait4(-1, &ws, syscall.WNOHANG, nil)
+		if err != nil || wpid <= 0 {
+			return 0, false
+		}
+		if wpid == pid {
+			return utils.ExitStatus(ws), true
+		}
+	}
+}
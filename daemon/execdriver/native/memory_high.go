@@ -0,0 +1,153 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/libcontainer"
+)
+
+const (
+	// memoryHighInterval is how often the memory usage of a container with a
+	// memory high watermark is checked.
+	memoryHighInterval = time.Second
+	// memoryHighQuota is the fraction of a cpu period a container above its
+	// memory high watermark may run for.
+	memoryHighQuota = 10
+)
+
+// memoryHighThrottler cuts the cpu quota of a container while its memory
+// usage is above its high watermark.
+type memoryHighThrottler struct {
+	id          string
+	container   libcontainer.Container
+	memory, cpu string // cgroup paths
+	high        uint64
+	period      uint64
+	throttled   bool
+}
+
+// throttleMemoryHigh emulates the memory.high watermark of the unified cgroup
+// hierarchy.  The high watermark is set as the soft limit of the memory
+// cgroup so the kernel reclaims the container first, and while the usage is
+// above it the container's cpu quota is cut so that it slows down instead of
+// running into its hard limit and the OOM killer.  Update notifies the
+// throttler when it changes the cpu quota of the container.  It returns when
+// done is closed.
+func (d *driver) throttleMemoryHigh(c *execdriver.Command, container libcontainer.Container, done <-chan struct{}) {
+	state, err := container.State()
+	if err != nil {
+		logrus.Warnf("Failed to throttle %s above its memory high watermark: %v", c.ID, err)
+		return
+	}
+	t := &memoryHighThrottler{
+		id:        c.ID,
+		container: container,
+		memory:    state.CgroupPaths["memory"],
+		cpu:       state.CgroupPaths["cpu"],
+		high:      uint64(c.Resources.MemoryHigh),
+	}
+	if t.memory == "" || t.cpu == "" {
+		logrus.Warnf("Failed to throttle %s above its memory high watermark: memory and cpu cgroups are required", c.ID)
+		return
+	}
+	if t.period, err = readCgroupUint(filepath.Join(t.cpu, "cpu.cfs_period_us")); err != nil || t.period == 0 {
+		logrus.Warnf("Failed to throttle %s above its memory high watermark: cpu cfs quota is not supported", c.ID)
+		return
+	}
+
+	updates := make(chan struct{}, 1)
+	d.Lock()
+	d.memoryHigh[c.ID] = updates
+	d.Unlock()
+	defer func() {
+		d.Lock()
+		delete(d.memoryHigh, c.ID)
+		d.Unlock()
+	}()
+
+	ticker := time.NewTicker(memoryHighInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-updates:
+			t.updated()
+		case <-ticker.C:
+			t.check()
+		}
+	}
+}
+
+// notifyMemoryHigh tells the memory high throttler of the container, if
+// any, that its cpu quota was updated.
+func (d *driver) notifyMemoryHigh(id string) {
+	d.Lock()
+	updates := d.memoryHigh[id]
+	d.Unlock()
+	select {
+	case updates <- struct{}{}:
+	default:
+	}
+}
+
+// check throttles the container when its memory usage goes above the high
+// watermark and gives it its cpu quota back when it goes below.
+func (t *memoryHighThrottler) check() {
+	usage, err := readCgroupUint(filepath.Join(t.memory, "memory.usage_in_bytes"))
+	if err != nil {
+		logrus.Debugf("Failed to read memory usage of %s: %v", t.id, err)
+		return
+	}
+	var value string
+	switch {
+	case usage > t.high && !t.throttled:
+		value = t.throttledQuota()
+	case usage <= t.high && t.throttled:
+		value = t.quota()
+	default:
+		return
+	}
+	if err := t.setQuota(value); err != nil {
+		return
+	}
+	t.throttled = !t.throttled
+	logrus.Debugf("Memory usage of %s is %d bytes, high watermark %d, throttled: %v", t.id, usage, t.high, t.throttled)
+}
+
+// updated cuts the quota of a throttled container again after an update
+// replaced it.  The new quota is given back once the usage goes below the
+// high watermark.
+func (t *memoryHighThrottler) updated() {
+	if t.throttled {
+		t.setQuota(t.throttledQuota())
+	}
+}
+
+// quota returns the current cpu quota of the container, which is changed by
+// updates.
+func (t *memoryHighThrottler) quota() string {
+	if cgroup := t.container.Config().Cgroups; cgroup != nil && cgroup.CpuQuota > 0 {
+		return strconv.FormatInt(cgroup.CpuQuota, 10)
+	}
+	return "-1"
+}
+
+func (t *memoryHighThrottler) throttledQuota() string {
+	return strconv.FormatUint(t.period/memoryHighQuota, 10)
+}
+
+func (t *memoryHighThrottler) setQuota(value string) error {
+	if err := ioutil.WriteFile(filepath.Join(t.cpu, "cpu.cfs_quota_us"), []byte(value), 0644); err != nil {
+		logrus.Debugf("Failed to set cpu quota of %s: %v", t.id, err)
+		return err
+	}
+	return nil
+}
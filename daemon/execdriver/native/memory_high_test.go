@@ -0,0 +1,73 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/libcontainer"
+	"github.com/docker/libcontainer/configs"
+)
+
+// quotaContainer is a container with the cpu quota of its config.
+type quotaContainer struct {
+	libcontainer.Container
+	quota int64
+}
+
+func (c *quotaContainer) Config() configs.Config {
+	return configs.Config{Cgroups: &configs.Cgroup{CpuQuota: c.quota}}
+}
+
+func TestMemoryHighThrottler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-memory-high-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	container := &quotaContainer{quota: 50000}
+	th := &memoryHighThrottler{id: "web", container: container, memory: dir, cpu: dir, high: 1000, period: 100000}
+	setUsage := func(usage string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, "memory.usage_in_bytes"), []byte(usage), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkQuota := func(expected string) {
+		data, err := ioutil.ReadFile(filepath.Join(dir, "cpu.cfs_quota_us"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if quota := strings.TrimSpace(string(data)); quota != expected {
+			t.Fatalf("expected a cpu quota of %s got %s", expected, quota)
+		}
+	}
+
+	setUsage("2000")
+	th.check()
+	checkQuota("10000")
+
+	// an update while throttled replaces the cut quota
+	container.quota = 80000
+	if err := ioutil.WriteFile(filepath.Join(dir, "cpu.cfs_quota_us"), []byte("80000"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	th.updated()
+	checkQuota("10000")
+
+	// the container gets the updated quota back
+	setUsage("500")
+	th.check()
+	checkQuota("80000")
+
+	container.quota = 0
+	setUsage("2000")
+	th.check()
+	setUsage("500")
+	th.check()
+	checkQuota("-1")
+}
@@ -0,0 +1,62 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"github.com/docker/docker/daemon/execdriver"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the latency histograms.
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// driverMetrics instruments the operations of the driver.
+type driverMetrics struct {
+	startLatency   *execdriver.Histogram
+	restoreLatency *execdriver.Histogram
+	failedStarts   *execdriver.Counter
+	oomKills       *execdriver.Counter
+}
+
+func newDriverMetrics() *driverMetrics {
+	return &driverMetrics{
+		startLatency: execdriver.NewHistogram(
+			"docker_native_container_start_seconds",
+			"Time from the start request to the container's init process running.",
+			latencyBuckets,
+		),
+		restoreLatency: execdriver.NewHistogram(
+			"docker_native_container_restore_seconds",
+			"Time to load a container left running by a previous daemon.",
+			latencyBuckets,
+		),
+		failedStarts: execdriver.NewCounter(
+			"docker_native_container_start_failures_total",
+			"Containers that failed to start by the step that failed.",
+			"reason",
+		),
+		oomKills: execdriver.NewCounter(
+			"docker_native_container_oom_kills_total",
+			"Containers killed by the OOM killer.",
+			"",
+		),
+	}
+}
+
+// Metrics returns a snapshot of the metrics of the driver.
+func (d *driver) Metrics() []execdriver.Metric {
+	d.Lock()
+	active := len(d.activeContainers)
+	d.Unlock()
+	return []execdriver.Metric{
+		{
+			Name:    "docker_native_containers_active",
+			Help:    "Containers currently managed by the driver.",
+			Type:    execdriver.MetricGauge,
+			Samples: []execdriver.MetricSample{{Value: float64(active)}},
+		},
+		d.metrics.startLatency.Metric(),
+		d.metrics.restoreLatency.Metric(),
+		d.metrics.failedStarts.Metric(),
+		d.metrics.oomKills.Metric(),
+	}
+}
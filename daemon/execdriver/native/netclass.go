@@ -0,0 +1,168 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/pkg/stringutils"
+	"github.com/docker/libcontainer"
+	"github.com/docker/libcontainer/cgroups"
+)
+
+// netCgroupDirs returns the directories of the container's net_cls and
+// net_prio cgroups, which are laid out like its cpu cgroup.  libcontainer
+// does not manage these subsystems so the driver places the processes itself.
+// Both directories are the same when the subsystems are mounted together.
+func netCgroupDirs(container libcontainer.Container) (netCls, netPrio string, err error) {
+	state, err := container.State()
+	if err != nil {
+		return "", "", err
+	}
+	cpu, ok := state.CgroupPaths["cpu"]
+	if !ok {
+		return "", "", fmt.Errorf("cpu cgroup is not available for %s", container.ID())
+	}
+	cpuMount, err := cgroups.FindCgroupMountpoint("cpu")
+	if err != nil {
+		return "", "", err
+	}
+	rel, err := filepath.Rel(cpuMount, cpu)
+	if err != nil {
+		return "", "", err
+	}
+	if mount, err := cgroups.FindCgroupMountpoint("net_cls"); err == nil {
+		netCls = filepath.Join(mount, rel)
+	}
+	if mount, err := cgroups.FindCgroupMountpoint("net_prio"); err == nil {
+		netPrio = filepath.Join(mount, rel)
+	}
+	return netCls, netPrio, nil
+}
+
+// setupNetClass creates the net_cls and net_prio cgroups of the container
+// with the class id and interface priorities of the command and moves the
+// process into them.  It returns the created directories.
+func setupNetClass(c *execdriver.Command, container libcontainer.Container, pid int) ([]string, error) {
+	if c.NetClassID == 0 && len(c.NetPrioMap) == 0 {
+		return nil, nil
+	}
+	netCls, netPrio, err := netCgroupDirs(container)
+	if err != nil {
+		return nil, err
+	}
+	type write struct{ dir, file, value string }
+	var writes []write
+	if c.NetClassID != 0 {
+		if netCls == "" {
+			return nil, fmt.Errorf("net_cls cgroup is not mounted")
+		}
+		writes = append(writes, write{netCls, "net_cls.classid", strconv.FormatUint(uint64(c.NetClassID), 10)})
+	}
+	if len(c.NetPrioMap) > 0 && netPrio == "" {
+		return nil, fmt.Errorf("net_prio cgroup is not mounted")
+	}
+	// net_prio.ifpriomap takes one interface per write
+	for iface, prio := range c.NetPrioMap {
+		writes = append(writes, write{netPrio, "net_prio.ifpriomap", fmt.Sprintf("%s %d", iface, prio)})
+	}
+
+	var dirs []string
+	for _, w := range writes {
+		if !stringutils.InSlice(dirs, w.dir) {
+			if err := os.MkdirAll(w.dir, 0755); err != nil {
+				return dirs, err
+			}
+			dirs = append(dirs, w.dir)
+		}
+		if err := writeCgroupFile(filepath.Join(w.dir, w.file), w.value); err != nil {
+			return dirs, fmt.Errorf("set %s of %s: %v", w.file, c.ID, err)
+		}
+	}
+	for _, dir := range dirs {
+		if err := writeCgroupFile(filepath.Join(dir, "cgroup.procs"), strconv.Itoa(pid)); err != nil {
+			return dirs, err
+		}
+	}
+	return dirs, nil
+}
+
+// joinNetClass moves a process exec'd in the container into the container's
+// net_cls and net_prio cgroups, if it has any.
+func joinNetClass(c *execdriver.Command, container libcontainer.Container, pid int) error {
+	if c.NetClassID == 0 && len(c.NetPrioMap) == 0 {
+		return nil
+	}
+	netCls, netPrio, err := netCgroupDirs(container)
+	if err != nil {
+		return err
+	}
+	for _, dir := range []string{netCls, netPrio} {
+		if dir == "" {
+			continue
+		}
+		if err := writeCgroupFile(filepath.Join(dir, "cgroup.procs"), strconv.Itoa(pid)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeNetClass removes the net_cls and net_prio cgroups of the container
+// once it exited.
+func removeNetClass(dirs []string) {
+	for _, dir := range dirs {
+		if err := os.Remove(dir); err != nil && !os.IsNotExist(err) {
+			logrus.Debugf("Failed to remove cgroup %s: %v", dir, err)
+		}
+	}
+}
+
+// NetClass returns the network class id and the interface priorities of the
+// container.
+func (i *info) NetClass() (uint32, map[string]int, error) {
+	i.driver.Lock()
+	active := i.driver.activeContainers[i.ID]
+	i.driver.Unlock()
+	if active == nil {
+		return 0, nil, &execdriver.ErrContainerNotActive{ID: i.ID}
+	}
+	netCls, netPrio, err := netCgroupDirs(active)
+	if err != nil {
+		return 0, nil, err
+	}
+	var classID uint64
+	if netCls != "" {
+		if classID, err = readCgroupUint(filepath.Join(netCls, "net_cls.classid")); err != nil {
+			return 0, nil, err
+		}
+	}
+	prioMap := make(map[string]int)
+	if netPrio != "" {
+		data, err := ioutil.ReadFile(filepath.Join(netPrio, "net_prio.ifpriomap"))
+		if err != nil && !os.IsNotExist(err) {
+			return 0, nil, err
+		}
+		s := bufio.NewScanner(strings.NewReader(string(data)))
+		for s.Scan() {
+			fields := strings.Fields(s.Text())
+			if len(fields) != 2 {
+				continue
+			}
+			prio, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return 0, nil, err
+			}
+			prioMap[fields[0]] = prio
+		}
+	}
+	return uint32(classID), prioMap, nil
+}
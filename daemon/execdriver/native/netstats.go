@@ -0,0 +1,65 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/docker/libcontainer"
+)
+
+// readNetDev returns the statistics of the interfaces in the network
+// namespace of the process.  The loopback interface is left out as its
+// traffic never leaves the container.
+func readNetDev(pid int) ([]*libcontainer.NetworkInterface, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/net/dev", pid))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseNetDev(f)
+}
+
+// parseNetDev parses the format of /proc/net/dev: two header lines then a
+// line per interface with its name followed by 8 receive and 8 transmit
+// counters.
+func parseNetDev(r io.Reader) ([]*libcontainer.NetworkInterface, error) {
+	var ifaces []*libcontainer.NetworkInterface
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		parts := strings.SplitN(s.Text(), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		name := strings.TrimSpace(parts[0])
+		fields := strings.Fields(parts[1])
+		if name == "lo" || len(fields) < 16 {
+			continue
+		}
+		var values [16]uint64
+		for i := range values {
+			v, err := strconv.ParseUint(fields[i], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid statistics of %s: %v", name, err)
+			}
+			values[i] = v
+		}
+		ifaces = append(ifaces, &libcontainer.NetworkInterface{
+			Name:      name,
+			RxBytes:   values[0],
+			RxPackets: values[1],
+			RxErrors:  values[2],
+			RxDropped: values[3],
+			TxBytes:   values[8],
+			TxPackets: values[9],
+			TxErrors:  values[10],
+			TxDropped: values[11],
+		})
+	}
+	return ifaces, s.Err()
+}
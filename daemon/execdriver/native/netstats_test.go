@@ -0,0 +1,29 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"strings"
+	"testing"
+)
+
+const netDev = `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:     100       2    0    0    0     0          0         0      100       2    0    0    0     0       0          0
+  eth0: 1296       16    1    2    0     0          0         0      648        8    3    4    0     0       0          0
+`
+
+func TestParseNetDev(t *testing.T) {
+	ifaces, err := parseNetDev(strings.NewReader(netDev))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ifaces) != 1 {
+		t.Fatalf("expected 1 interface got %d", len(ifaces))
+	}
+	i := ifaces[0]
+	if i.Name != "eth0" || i.RxBytes != 1296 || i.RxPackets != 16 || i.RxErrors != 1 || i.RxDropped != 2 ||
+		i.TxBytes != 648 || i.TxPackets != 8 || i.TxErrors != 3 || i.TxDropped != 4 {
+		t.Fatalf("unexpected statistics %+v", i)
+	}
+}
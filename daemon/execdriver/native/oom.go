@@ -0,0 +1,195 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/libcontainer"
+	"github.com/docker/libcontainer/cgroups"
+)
+
+// applyOomScoreAdj sets the OOM score adjustment of the command on a process
+// started in the container.  Processes forked afterwards inherit it.
+func applyOomScoreAdj(c *execdriver.Command, p *libcontainer.Process) error {
+	if c.OomScoreAdj == 0 {
+		return nil
+	}
+	pid, err := p.Pid()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join("/proc", strconv.Itoa(pid), "oom_score_adj"), []byte(strconv.Itoa(c.OomScoreAdj)), 0644)
+}
+
+var killedProcessRegexp = regexp.MustCompile(`Killed process (\d+) \(([^)]*)\)`)
+
+// readOomScoreAdj returns the OOM score adjustment of the process, zero if it
+// cannot be read.
+func readOomScoreAdj(pid int) int {
+	data, err := ioutil.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "oom_score_adj"))
+	if err != nil {
+		return 0
+	}
+	adj, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return adj
+}
+
+// memoryCgroupName returns the path of the memory cgroup relative to the root
+// of the hierarchy, which is how the kernel refers to it in the OOM report.
+func memoryCgroupName(paths map[string]string) (string, error) {
+	mountpoint, err := cgroups.FindCgroupMountpoint("memory")
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(mountpoint, paths["memory"])
+	if err != nil {
+		return "", err
+	}
+	return "/" + rel, nil
+}
+
+// readOOMReport looks up the last OOM kill of the memory cgroup in the kernel
+// log.  It returns nil if no report can be found.
+func readOOMReport(cgroup string) (*execdriver.OOMReport, error) {
+	records, err := readKmsg()
+	if err != nil {
+		return nil, err
+	}
+	return parseOOMReport(kmsgMessages(records), cgroup), nil
+}
+
+// kmsgRecord is a message of the kernel log.
+type kmsgRecord struct {
+	seq     uint64
+	message string
+}
+
+// readKmsg returns the records currently in the kernel ring buffer.
+func readKmsg() ([]kmsgRecord, error) {
+	f, err := os.OpenFile("/dev/kmsg", os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		records []kmsgRecord
+		buf     = make([]byte, 8192)
+	)
+	for {
+		n, err := syscall.Read(int(f.Fd()), buf)
+		if err != nil {
+			if err == syscall.EPIPE {
+				// the record was overwritten while we were reading
+				continue
+			}
+			if err == syscall.EAGAIN {
+				return records, nil
+			}
+			return nil, err
+		}
+		// records are formatted as "prio,seq,time,flags;message\n" optionally
+		// followed by continuation lines holding key/value pairs
+		record := strings.SplitN(string(buf[:n]), "\n", 2)[0]
+		i := strings.Index(record, ";")
+		if i < 0 {
+			continue
+		}
+		var seq uint64
+		if fields := strings.SplitN(record[:i], ",", 3); len(fields) > 1 {
+			seq, _ = strconv.ParseUint(fields[1], 10, 64)
+		}
+		records = append(records, kmsgRecord{seq: seq, message: record[i+1:]})
+	}
+}
+
+func kmsgMessages(records []kmsgRecord) []string {
+	lines := make([]string, len(records))
+	for i, r := range records {
+		lines[i] = r.message
+	}
+	return lines
+}
+
+// parseOOMReport extracts the last OOM killer report for the cgroup from the
+// kernel log lines.
+func parseOOMReport(lines []string, cgroup string) *execdriver.OOMReport {
+	var (
+		report  *execdriver.OOMReport
+		start   = -1
+		matched bool
+	)
+	for i, line := range lines {
+		switch {
+		case strings.Contains(line, "invoked oom-killer"):
+			start, matched = i, false
+		case strings.Contains(line, "Task in "+cgroup+" killed"),
+			strings.Contains(line, "oom_memcg="+cgroup+","):
+			matched = true
+		case strings.Contains(line, "Killed process") && matched && start >= 0:
+			report = newOOMReport(lines[start : i+1])
+			start, matched = -1, false
+		}
+	}
+	return report
+}
+
+func newOOMReport(lines []string) *execdriver.OOMReport {
+	report := &execdriver.OOMReport{
+		Message: strings.Join(lines, "\n"),
+	}
+	var columns []string
+	for _, line := range lines {
+		if m := killedProcessRegexp.FindStringSubmatch(line); m != nil {
+			report.VictimPid, _ = strconv.Atoi(m[1])
+			report.VictimComm = m[2]
+			continue
+		}
+		if !strings.HasPrefix(line, "[") {
+			continue
+		}
+		i := strings.Index(line, "]")
+		if i < 0 {
+			continue
+		}
+		pid, fields := strings.TrimSpace(line[1:i]), strings.Fields(line[i+1:])
+		if pid == "pid" {
+			// the header tells which column holds what, it differs between
+			// kernel versions
+			columns = fields
+			continue
+		}
+		if p, ok := parseOOMProcess(pid, fields, columns); ok {
+			report.Processes = append(report.Processes, p)
+		}
+	}
+	return report
+}
+
+func parseOOMProcess(pid string, fields, columns []string) (execdriver.OOMProcess, bool) {
+	var p execdriver.OOMProcess
+	if len(columns) == 0 || len(fields) != len(columns) {
+		return p, false
+	}
+	var err error
+	if p.Pid, err = strconv.Atoi(pid); err != nil {
+		return p, false
+	}
+	for i, column := range columns {
+		switch column {
+		case "rss":
+			p.RSS, _ = strconv.ParseUint(fields[i], 10, 64)
+		case "name":
+			p.Comm = fields[i]
+		}
+	}
+	return p, true
+}
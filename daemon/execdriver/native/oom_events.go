@@ -0,0 +1,149 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/libcontainer"
+)
+
+const (
+	// oomReportTimeout is how long the kernel is given to log the report of
+	// an OOM kill once it is notified.
+	oomReportTimeout = time.Second
+	// oomReportInterval is how often the kernel log is read while waiting
+	// for a report.
+	oomReportInterval = 50 * time.Millisecond
+)
+
+// SubscribeOOM returns a channel that receives an event for each OOM kill in
+// the container while it runs.  The channel is closed when the container
+// exits.  Events are dropped if the subscriber does not keep up.
+func (d *driver) SubscribeOOM(id string) (<-chan execdriver.OOMEvent, error) {
+	d.Lock()
+	defer d.Unlock()
+	if d.activeContainers[id] == nil {
+		return nil, &execdriver.ErrContainerNotActive{ID: id}
+	}
+	ch := make(chan execdriver.OOMEvent, 16)
+	d.oomSubscribers[id] = append(d.oomSubscribers[id], ch)
+	return ch, nil
+}
+
+// watchOOM publishes an event to the subscribers of the container for each
+// OOM notification.  The returned channel receives whether there was any OOM
+// notification once the notifications stop, which happens when the
+// container's cgroup is removed.
+func (d *driver) watchOOM(id string, container libcontainer.Container, memoryCgroup string) <-chan bool {
+	var memoryPath string
+	if state, err := container.State(); err == nil {
+		memoryPath = state.CgroupPaths["memory"]
+	}
+	var reporter *oomReporter
+	if memoryCgroup != "" {
+		reporter = newOOMReporter(memoryCgroup, readKmsg)
+	}
+	oom := notifyOnOOM(container)
+	result := make(chan bool, 1)
+	go func() {
+		var oomKill bool
+		for range oom {
+			oomKill = true
+			d.publishOOM(id, newOOMEvent(reporter, memoryPath))
+		}
+		result <- oomKill
+	}()
+	return result
+}
+
+// newOOMEvent describes an OOM kill that was just notified.
+func newOOMEvent(reporter *oomReporter, memoryPath string) execdriver.OOMEvent {
+	event := execdriver.OOMEvent{Time: time.Now()}
+	if memoryPath != "" {
+		usage, err := readCgroupUint(filepath.Join(memoryPath, "memory.usage_in_bytes"))
+		if err != nil {
+			logrus.Debugf("Failed to read memory usage of %s: %v", memoryPath, err)
+		}
+		event.MemoryUsage = usage
+	}
+	if reporter != nil {
+		if report := reporter.next(); report != nil {
+			event.VictimPid = report.VictimPid
+			event.VictimComm = report.VictimComm
+		}
+	}
+	return event
+}
+
+// oomReporter finds the reports of the successive OOM kills of a memory
+// cgroup in the kernel log.
+type oomReporter struct {
+	cgroup   string
+	readKmsg func() ([]kmsgRecord, error)
+	timeout  time.Duration
+	// seen is the sequence number of the last message read for a report,
+	// the report of the next OOM kill comes after it.
+	seen uint64
+}
+
+// newOOMReporter returns a reporter for the OOM kills that happen from now
+// on: the reports of a previous container with the same cgroup are skipped.
+func newOOMReporter(cgroup string, readKmsg func() ([]kmsgRecord, error)) *oomReporter {
+	r := &oomReporter{cgroup: cgroup, readKmsg: readKmsg, timeout: oomReportTimeout}
+	records, err := readKmsg()
+	if err != nil {
+		logrus.Debugf("Failed to read the kernel log: %v", err)
+	}
+	if len(records) > 0 {
+		r.seen = records[len(records)-1].seq
+	}
+	return r
+}
+
+// next returns the report of the OOM kill that was just notified.  The kernel
+// may log it after the notification so the kernel log is read until the
+// report shows up or the timeout expires, in which case it returns nil.
+func (r *oomReporter) next() *execdriver.OOMReport {
+	deadline := time.Now().Add(r.timeout)
+	for {
+		records, err := r.readKmsg()
+		if err != nil {
+			logrus.Debugf("Failed to read the OOM report of %s: %v", r.cgroup, err)
+			return nil
+		}
+		var (
+			lines []string
+			last  uint64
+		)
+		for _, record := range records {
+			if record.seq > r.seen {
+				lines = append(lines, record.message)
+				last = record.seq
+			}
+		}
+		if report := parseOOMReport(lines, r.cgroup); report != nil {
+			r.seen = last
+			return report
+		}
+		if time.Now().After(deadline) {
+			logrus.Debugf("Failed to find the OOM report of %s in the kernel log", r.cgroup)
+			return nil
+		}
+		time.Sleep(oomReportInterval)
+	}
+}
+
+func (d *driver) publishOOM(id string, event execdriver.OOMEvent) {
+	d.Lock()
+	defer d.Unlock()
+	for _, ch := range d.oomSubscribers[id] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/libcontainer"
+)
+
+var oomLog = []string{
+	"eth0: link up",
+	"stress invoked oom-killer: gfp_mask=0xd0, order=0, oom_score_adj=0",
+	"Task in /docker/other killed as a result of limit of /docker/other",
+	"Memory cgroup out of memory: Kill process 100 (other) score 999 or sacrifice child",
+	"Killed process 100 (other) total-vm:7428kB, anon-rss:100kB, file-rss:0kB",
+	"stress invoked oom-killer: gfp_mask=0xd0, order=0, oom_score_adj=0",
+	"Task in /docker/abc killed as a result of limit of /docker/abc",
+	"memory: usage 102400kB, limit 102400kB, failcnt 42",
+	"[ pid ]   uid  tgid total_vm      rss nr_ptes swapents oom_score_adj name",
+	"[ 4242]     0  4242     1118       17       8        0             0 sh",
+	"[ 4250]     0  4250    26741    25606      59        0             0 stress",
+	"Memory cgroup out of memory: Kill process 4250 (stress) score 1000 or sacrifice child",
+	"Killed process 4250 (stress) total-vm:106964kB, anon-rss:102064kB, file-rss:360kB",
+}
+
+func TestParseOOMReport(t *testing.T) {
+	report := parseOOMReport(oomLog, "/docker/abc")
+	if report == nil {
+		t.Fatal("expected a report for /docker/abc")
+	}
+	if report.VictimPid != 4250 || report.VictimComm != "stress" {
+		t.Fatalf("expected victim 4250 (stress) got %d (%s)", report.VictimPid, report.VictimComm)
+	}
+	if len(report.Processes) != 2 {
+		t.Fatalf("expected 2 processes got %d", len(report.Processes))
+	}
+	if p := report.Processes[1]; p.Pid != 4250 || p.Comm != "stress" || p.RSS != 25606 {
+		t.Fatalf("unexpected process entry %+v", p)
+	}
+}
+
+func TestParseOOMReportNewKernel(t *testing.T) {
+	lines := []string{
+		"stress invoked oom-killer: gfp_mask=0xcc0(GFP_KERNEL), order=0, oom_score_adj=0",
+		"[  pid  ]   uid  tgid total_vm      rss pgtables_bytes swapents oom_score_adj name",
+		"[   4250]     0  4250    26741    25606   241664        0             0 stress",
+		"oom-kill:constraint=CONSTRAINT_MEMCG,nodemask=(null),oom_memcg=/docker/abc,task_memcg=/docker/abc,task=stress,pid=4250,uid=0",
+		"Memory cgroup out of memory: Killed process 4250 (stress) total-vm:106964kB, anon-rss:102064kB, file-rss:360kB",
+	}
+	report := parseOOMReport(lines, "/docker/abc")
+	if report == nil {
+		t.Fatal("expected a report for /docker/abc")
+	}
+	if report.VictimPid != 4250 || len(report.Processes) != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
+
+func TestParseOOMReportNoMatch(t *testing.T) {
+	if report := parseOOMReport(oomLog, "/docker/missing"); report != nil {
+		t.Fatalf("expected no report got %+v", report)
+	}
+}
+
+// kmsg is a kernel log that grows each time it is read.
+type kmsg struct {
+	records []kmsgRecord
+	logged  int // number of records logged so far
+	step    int // number of records logged per read
+}
+
+func newKmsg(lines []string, logged, step int) *kmsg {
+	k := &kmsg{logged: logged, step: step}
+	for i, line := range lines {
+		k.records = append(k.records, kmsgRecord{seq: uint64(i + 1), message: line})
+	}
+	return k
+}
+
+func (k *kmsg) read() ([]kmsgRecord, error) {
+	records := k.records[:k.logged]
+	if k.logged += k.step; k.logged > len(k.records) {
+		k.logged = len(k.records)
+	}
+	return records, nil
+}
+
+func TestOOMReporterWaitsForReport(t *testing.T) {
+	// the report of the first OOM kill of /docker/abc is logged after the
+	// notification
+	k := newKmsg(oomLog, 5, 2)
+	r := newOOMReporter("/docker/abc", k.read)
+	r.timeout = time.Second
+	report := r.next()
+	if report == nil || report.VictimPid != 4250 {
+		t.Fatalf("expected the report of the OOM kill of 4250 got %+v", report)
+	}
+}
+
+func TestOOMReporterMissingReport(t *testing.T) {
+	// the reports logged before the reporter started belong to a previous
+	// OOM kill
+	k := newKmsg(oomLog, len(oomLog), 0)
+	r := newOOMReporter("/docker/abc", k.read)
+	r.timeout = 10 * time.Millisecond
+	if report := r.next(); report != nil {
+		t.Fatalf("expected no report when it is not logged got %+v", report)
+	}
+
+	// a report is returned once
+	k = newKmsg(oomLog, 0, len(oomLog))
+	r = newOOMReporter("/docker/abc", k.read)
+	r.timeout = 10 * time.Millisecond
+	if report := r.next(); report == nil {
+		t.Fatal("expected the report of the OOM kill")
+	}
+	if report := r.next(); report != nil {
+		t.Fatalf("expected the report returned once got %+v", report)
+	}
+}
+
+func TestSubscribeOOMNotActive(t *testing.T) {
+	d := &driver{activeContainers: make(map[string]libcontainer.Container)}
+	if _, err := d.SubscribeOOM("web"); err == nil {
+		t.Fatal("expected an error for a container that is not running")
+	} else if _, ok := err.(*execdriver.ErrContainerNotActive); !ok {
+		t.Fatalf("expected ErrContainerNotActive for a container that is not running, got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/parsers"
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/docker/docker/pkg/units"
+	"github.com/docker/libcontainer"
+)
+
+// driverOptions are the settings of the driver given as exec options.
+type driverOptions struct {
+	cgroupDriver      string // name of a registered cgroup manager
+	coreDumpSize      int64  // -1 when core dumps are not routed
+	nonBlockingRandom bool
+	cpuRtRequired     bool        // real-time scheduling is enabled
+	rootMode          os.FileMode // permissions of the state directories
+	stdioBuffer       int         // size of the buffers copying stdio
+	stdioRate         int64       // bytes per second of output, 0 for no limit
+}
+
+// optionParsers validate the value of each exec option and store it in the
+// driver options.  New options only need an entry here.
+var optionParsers = map[string]func(opts *driverOptions, val string) error{
+	"native.cgroupdriver":  parseCgroupDriver,
+	"native.coredumpsize":  parseCoreDumpSize,
+	"native.random":        parseRandom,
+	"native.cpurtrequired": parseCpuRtRequired,
+	"native.rootmode":      parseRootMode,
+	"native.stdiobuffer":   parseStdioBuffer,
+	"native.stdiorate":     parseStdioRate,
+}
+
+func parseCgroupDriver(opts *driverOptions, val string) error {
+	// override the default if they set options
+	f, ok := cgroupManagers[val]
+	if !ok {
+		return fmt.Errorf("Unknown native.cgroupdriver given %q. try %s", val, strings.Join(CgroupManagers(), " or "))
+	}
+	if !f.Available() {
+		// warn them that they chose the wrong driver
+		logrus.Warnf("You cannot use %s as native.cgroupdriver, using cgroupfs instead", val)
+		return nil
+	}
+	opts.cgroupDriver = val
+	return nil
+}
+
+// parseCoreDumpSize routes core dumps of container processes to the driver's
+// root, truncating them to the given size.  Core dumps are not routed unless
+// the option is set.
+func parseCoreDumpSize(opts *driverOptions, val string) error {
+	size, err := units.RAMInBytes(val)
+	if err != nil {
+		return fmt.Errorf("Invalid native.coredumpsize given %q: %v", val, err)
+	}
+	opts.coreDumpSize = size
+	return nil
+}
+
+// parseRandom sets the source backing /dev/random in containers.
+func parseRandom(opts *driverOptions, val string) error {
+	switch val {
+	case "urandom":
+		opts.nonBlockingRandom = true
+	case "random":
+		opts.nonBlockingRandom = false
+	default:
+		return fmt.Errorf("Unknown native.random given %q. try random or urandom", val)
+	}
+	return nil
+}
+
+// parseCpuRtRequired enables real-time scheduling of containers, which
+// requires cpu.rt_runtime_us budgets to be given out of the host's.
+func parseCpuRtRequired(opts *driverOptions, val string) error {
+	required, err := strconv.ParseBool(val)
+	if err != nil {
+		return fmt.Errorf("Invalid native.cpurtrequired given %q: %v", val, err)
+	}
+	opts.cpuRtRequired = required
+	return nil
+}
+
+// parseRootMode sets the permissions, in octal, of the driver's root and of
+// the state directories of the containers, for tools reading the state of
+// containers without root privileges.
+func parseRootMode(opts *driverOptions, val string) error {
+	mode, err := strconv.ParseUint(val, 8, 32)
+	if err != nil || mode&^0777 != 0 {
+		return fmt.Errorf("Invalid native.rootmode given %q. try an octal mode such as 0750", val)
+	}
+	opts.rootMode = os.FileMode(mode)
+	return nil
+}
+
+// parseStdioBuffer sets the size of the buffers copying the stdio of
+// containers.
+func parseStdioBuffer(opts *driverOptions, val string) error {
+	size, err := units.RAMInBytes(val)
+	if err != nil || size < 512 || size > 16*1024*1024 {
+		return fmt.Errorf("Invalid native.stdiobuffer given %q. try a size between 512b and 16m", val)
+	}
+	opts.stdioBuffer = int(size)
+	return nil
+}
+
+// parseStdioRate limits the output of each container to the given bytes per
+// second.  A container writing faster is blocked on its stdout and stderr.
+func parseStdioRate(opts *driverOptions, val string) error {
+	rate, err := units.RAMInBytes(val)
+	if err != nil || rate < 0 {
+		return fmt.Errorf("Invalid native.stdiorate given %q. try a size per second such as 1m", val)
+	}
+	opts.stdioRate = rate
+	return nil
+}
+
+// parseOptions validates the options and returns the resulting settings.
+func parseOptions(options []string) (*driverOptions, error) {
+	// choose cgroup manager
+	// this makes sure there are no breaking changes to people
+	// who upgrade from versions without native.cgroupdriver opt
+	opts := &driverOptions{
+		cgroupDriver: "cgroupfs",
+		coreDumpSize: -1,
+		rootMode:     0700,
+		stdioBuffer:  defaultStdioBuffer,
+	}
+	if cgroupManagers["systemd"].Available() {
+		opts.cgroupDriver = "systemd"
+	}
+
+	for _, option := range options {
+		key, val, err := parsers.ParseKeyValueOpt(option)
+		if err != nil {
+			return nil, err
+		}
+		key = strings.ToLower(key)
+		parse, ok := optionParsers[key]
+		if !ok {
+			return nil, fmt.Errorf("Unknown option %s\n", key)
+		}
+		if err := parse(opts, val); err != nil {
+			return nil, err
+		}
+	}
+	return opts, nil
+}
+
+// cgroupManager returns the libcontainer cgroup manager for the cgroup driver.
+func (o *driverOptions) cgroupManager() func(*libcontainer.LinuxFactory) error {
+	return cgroupManagers[o.cgroupDriver].Option()
+}
+
+// Reload applies changed exec options without restarting the daemon.  The
+// options are validated before anything is applied.  Running containers keep
+// the settings they were started with, new containers get the new ones.
+// Containers are loaded with the cgroup driver they were started with.  Every
+// option is read when a container is started, or applied here, so none of
+// them needs a restart of the daemon.
+func (d *driver) Reload(options []string) error {
+	opts, err := parseOptions(options)
+	if err != nil {
+		return err
+	}
+
+	d.Lock()
+	defer d.Unlock()
+	if opts.cgroupDriver != d.options.cgroupDriver {
+		f, err := d.cgroupFactoryLocked(opts.cgroupDriver)
+		if err != nil {
+			return err
+		}
+		d.factory = f
+	}
+	if opts.coreDumpSize != d.options.coreDumpSize {
+		if opts.coreDumpSize >= 0 {
+			if err := setupCoreDumps(d.root, opts.coreDumpSize, reexec.Self()); err != nil {
+				return err
+			}
+		} else if err := restoreCoreDumps(); err != nil {
+			return err
+		}
+	}
+	if opts.rootMode != d.options.rootMode {
+		// the state directories of running containers keep their mode
+		if err := os.Chmod(d.root, opts.rootMode); err != nil {
+			return err
+		}
+	}
+	logrus.Debugf("Using %v as native.cgroupdriver", opts.cgroupDriver)
+	d.options = opts
+	return nil
+}
@@ -0,0 +1,131 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/libcontainer"
+	"github.com/docker/libcontainer/configs"
+)
+
+func TestParseOptions(t *testing.T) {
+	opts, err := parseOptions([]string{"native.cgroupdriver=cgroupfs", "native.random=urandom", "native.coredumpsize=1k", "native.cpurtrequired=true", "native.rootmode=0750", "native.stdiobuffer=64k", "native.stdiorate=1m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.cgroupDriver != "cgroupfs" || !opts.nonBlockingRandom || opts.coreDumpSize != 1024 || !opts.cpuRtRequired || opts.rootMode != 0750 || opts.stdioBuffer != 64*1024 || opts.stdioRate != 1024*1024 {
+		t.Fatalf("unexpected options %+v", opts)
+	}
+}
+
+func TestParseOptionsInvalid(t *testing.T) {
+	for _, option := range []string{"native.unknown=1", "native.random=zero", "native.cgroupdriver=lxc", "native.coredumpsize=big", "native.cpurtrequired=maybe", "native.rootmode=0888", "native.rootmode=01777", "native.stdiobuffer=1", "native.stdiorate=fast"} {
+		if _, err := parseOptions([]string{option}); err == nil {
+			t.Fatalf("expected an error for %s", option)
+		}
+	}
+}
+
+func TestRegisterCgroupManager(t *testing.T) {
+	if err := RegisterCgroupManager("cgroupfs", cgroupfsManager{}); err == nil {
+		t.Fatal("expected an error registering cgroupfs twice")
+	}
+	if _, err := parseOptions([]string{"native.cgroupdriver=unknown"}); err == nil {
+		t.Fatal("expected an error for an unregistered cgroup manager")
+	}
+}
+
+func TestCgroupParent(t *testing.T) {
+	cgroup := &configs.Cgroup{Parent: "docker"}
+	if err := (systemdManager{}).SetParent(cgroup, "system.slice/web.slice"); err != nil {
+		t.Fatal(err)
+	}
+	if cgroup.Slice != "system-web.slice" || cgroup.Parent != "docker" {
+		t.Fatalf("expected the slice system-web.slice, got %+v", cgroup)
+	}
+	for _, parent := range []string{"web", "/docker/web", "system.slice/web", ".slice"} {
+		if err := (systemdManager{}).SetParent(&configs.Cgroup{}, parent); err == nil {
+			t.Fatalf("expected an error for the slice %s", parent)
+		}
+	}
+
+	cgroup = &configs.Cgroup{}
+	if err := (cgroupfsManager{}).SetParent(cgroup, "/web//frontend/"); err != nil {
+		t.Fatal(err)
+	}
+	if cgroup.Parent != "/web/frontend" {
+		t.Fatalf("expected the parent /web/frontend, got %s", cgroup.Parent)
+	}
+	if err := (cgroupfsManager{}).SetParent(&configs.Cgroup{}, "/web/../../"); err == nil {
+		t.Fatal("expected an error for a parent outside of the hierarchy")
+	}
+}
+
+func TestLoadContainerCgroupDriver(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-native-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	d := &driver{
+		root:      root,
+		options:   &driverOptions{cgroupDriver: "systemd"},
+		factories: make(map[string]libcontainer.Factory),
+	}
+	for id, name := range map[string]string{"web": "cgroupfs", "db": "unknown"} {
+		if err := os.MkdirAll(filepath.Join(root, id), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.saveCgroupDriver(id, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the container has no state, only the factory it is loaded with matters
+	d.loadContainer("web")
+	if _, ok := d.factories["cgroupfs"]; !ok || len(d.factories) != 1 {
+		t.Fatalf("expected the container loaded with the cgroupfs driver it was started with, got %v", d.factories)
+	}
+	if _, err := d.loadContainer("db"); err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Fatalf("expected an error for an unknown cgroup driver got %v", err)
+	}
+}
+
+func TestReload(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-native-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	opts, err := parseOptions(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &driver{root: root, options: opts}
+	if err := d.Reload([]string{"native.random=urandom", "native.rootmode=0750", "native.stdiorate=1m"}); err != nil {
+		t.Fatal(err)
+	}
+	if !d.options.nonBlockingRandom || d.options.rootMode != 0750 || d.options.stdioRate != 1024*1024 {
+		t.Fatalf("expected the options applied, got %+v", d.options)
+	}
+	fi, err := os.Stat(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0750 {
+		t.Fatalf("expected the mode of the root changed to 0750, got %v", fi.Mode())
+	}
+
+	if err := d.Reload([]string{"native.random=zero"}); err == nil {
+		t.Fatal("expected an error for an invalid option")
+	}
+	if !d.options.nonBlockingRandom {
+		t.Fatal("expected the options kept after an invalid option")
+	}
+}
@@ -0,0 +1,47 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/libcontainer/cgroups"
+)
+
+// PerfCommand returns a perf command for the given subcommand (stat, record,
+// top, ...) that is scoped to the perf_event cgroup of the container.  The
+// command is not started so that the caller can wire up its output.
+func (d *driver) PerfCommand(id, subcommand string, args ...string) (*exec.Cmd, error) {
+	d.Lock()
+	active := d.activeContainers[id]
+	d.Unlock()
+	if active == nil {
+		return nil, execdriver.ErrNotRunning
+	}
+	state, err := active.State()
+	if err != nil {
+		return nil, err
+	}
+	path, ok := state.CgroupPaths["perf_event"]
+	if !ok {
+		return nil, fmt.Errorf("perf_event cgroup is not available for %s", id)
+	}
+	mountpoint, err := cgroups.FindCgroupMountpoint("perf_event")
+	if err != nil {
+		return nil, err
+	}
+	// perf expects the cgroup name relative to the perf_event mountpoint
+	name, err := filepath.Rel(mountpoint, path)
+	if err != nil {
+		return nil, err
+	}
+	perf, err := exec.LookPath("perf")
+	if err != nil {
+		return nil, err
+	}
+	// cgroup filtering is only available in system-wide mode
+	return exec.Command(perf, append([]string{subcommand, "-a", "-G", name}, args...)...), nil
+}
@@ -0,0 +1,140 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/libcontainer/system"
+)
+
+// GetProcesses describes the processes in the container's cgroup with the
+// details the kernel exposes in /proc.  Processes exiting meanwhile are
+// left out.
+func (d *driver) GetProcesses(id string) ([]*execdriver.ProcessInfo, error) {
+	d.Lock()
+	active := d.activeContainers[id]
+	d.Unlock()
+	if active == nil {
+		return nil, &execdriver.ErrContainerNotActive{ID: id}
+	}
+	pids, err := active.Processes()
+	if err != nil {
+		return nil, &execdriver.ErrCgroupFailure{ID: id, Err: err}
+	}
+	btime, err := bootTime("/proc")
+	if err != nil {
+		return nil, err
+	}
+	ticks := system.GetClockTicks()
+	var procs []*execdriver.ProcessInfo
+	for _, pid := range pids {
+		p, err := readProcessInfo("/proc", pid, ticks, btime)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		procs = append(procs, p)
+	}
+	return procs, nil
+}
+
+// readProcessInfo reads the stat, status and cmdline files of the process.
+func readProcessInfo(procRoot string, pid, clockTicks int, btime time.Time) (*execdriver.ProcessInfo, error) {
+	dir := filepath.Join(procRoot, strconv.Itoa(pid))
+	stat, err := ioutil.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return nil, err
+	}
+	// the command name may contain spaces and parentheses, the fields
+	// start after its closing parenthesis
+	i := bytes.LastIndex(stat, []byte(")"))
+	if i < 0 {
+		return nil, fmt.Errorf("invalid stat of process %d", pid)
+	}
+	comm := string(stat[bytes.IndexByte(stat, '(')+1 : i])
+	fields := strings.Fields(string(stat[i+1:]))
+	if len(fields) < 22 {
+		return nil, fmt.Errorf("invalid stat of process %d", pid)
+	}
+	var values [4]uint64
+	for j, field := range []int{11, 12, 19, 21} { // utime, stime, starttime, rss
+		if values[j], err = strconv.ParseUint(fields[field], 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid stat of process %d: %v", pid, err)
+		}
+	}
+	p := &execdriver.ProcessInfo{
+		Pid:       pid,
+		CpuTime:   time.Duration(values[0]+values[1]) * time.Second / time.Duration(clockTicks),
+		Rss:       values[3] * uint64(os.Getpagesize()),
+		StartTime: btime.Add(time.Duration(values[2]) * time.Second / time.Duration(clockTicks)),
+	}
+	if p.PPid, err = strconv.Atoi(fields[1]); err != nil {
+		return nil, fmt.Errorf("invalid stat of process %d: %v", pid, err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "status"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		// Uid: real, effective, saved and filesystem uid
+		if uid := strings.Fields(s.Text()); len(uid) > 1 && uid[0] == "Uid:" {
+			if p.Uid, err = strconv.Atoi(uid[1]); err != nil {
+				return nil, fmt.Errorf("invalid status of process %d: %v", pid, err)
+			}
+			break
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	cmdline, err := ioutil.ReadFile(filepath.Join(dir, "cmdline"))
+	if err != nil {
+		return nil, err
+	}
+	p.Cmdline = strings.TrimSpace(string(bytes.Replace(cmdline, []byte{0}, []byte{' '}, -1)))
+	if p.Cmdline == "" {
+		// kernel threads and zombies have no command line
+		p.Cmdline = "[" + comm + "]"
+	}
+	return p, nil
+}
+
+// bootTime returns the time the host booted at, which process start times
+// are relative to.
+func bootTime(procRoot string) (time.Time, error) {
+	f, err := os.Open(filepath.Join(procRoot, "stat"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if fields := strings.Fields(s.Text()); len(fields) == 2 && fields[0] == "btime" {
+			btime, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return time.Unix(btime, 0), nil
+		}
+	}
+	if err := s.Err(); err != nil {
+		return time.Time{}, err
+	}
+	return time.Time{}, fmt.Errorf("btime not found in %s", f.Name())
+}
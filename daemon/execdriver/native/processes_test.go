@@ -0,0 +1,69 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadProcessInfo(t *testing.T) {
+	root, err := ioutil.TempDir("", "proc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	dir := filepath.Join(root, "42")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{
+		"stat":    "42 (my (app)) S 1 42 42 0 -1 4202752 100 0 0 0 150 50 0 0 20 0 1 0 500 1000000 25 18446744073709551615",
+		"status":  "Name:\tmy (app)\nState:\tS (sleeping)\nUid:\t1000\t1000\t1000\t1000\nGid:\t0\t0\t0\t0\n",
+		"cmdline": "/bin/app\x00--flag\x00",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "stat"), []byte("cpu  1 2 3\nbtime 1000\nprocesses 10\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	btime, err := bootTime(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := readProcessInfo(root, 42, 100, btime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Pid != 42 || p.PPid != 1 || p.Uid != 1000 {
+		t.Fatalf("unexpected ids %+v", p)
+	}
+	if p.CpuTime != 2*time.Second {
+		t.Fatalf("expected 2s of cpu time, got %v", p.CpuTime)
+	}
+	if p.Rss != 25*uint64(os.Getpagesize()) {
+		t.Fatalf("unexpected rss %d", p.Rss)
+	}
+	if !p.StartTime.Equal(time.Unix(1005, 0)) {
+		t.Fatalf("unexpected start time %v", p.StartTime)
+	}
+	if p.Cmdline != "/bin/app --flag" {
+		t.Fatalf("unexpected command line %q", p.Cmdline)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "cmdline"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if p, err = readProcessInfo(root, 42, 100, btime); err != nil {
+		t.Fatal(err)
+	}
+	if p.Cmdline != "[my (app)]" {
+		t.Fatalf("unexpected command line %q", p.Cmdline)
+	}
+}
@@ -0,0 +1,157 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/pkg/mount"
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/docker/docker/pkg/symlink"
+	"github.com/docker/libcontainer/configs"
+)
+
+// propagationHelperPath is run by the container's init after bind mounting a
+// mount with a propagation, in the mount namespace of the container before
+// it pivots to its rootfs.  It is a symlink to the docker binary and, like
+// coreHelperPath, the path itself is registered with reexec.
+const propagationHelperPath = "/var/run/docker-mount-propagation"
+
+const (
+	// privateRootfsArg has the propagation helper make the mount of the
+	// rootfs private, so that what is mounted in the container does not
+	// reach the host through it.
+	privateRootfsArg = "--private-rootfs"
+	// slaveHostArg has the propagation helper make the mounts outside of the
+	// rootfs slaves, as pivot_root fails under a shared mount and unmounting
+	// the old root would unmount them on the host as well.
+	slaveHostArg = "--slave-host"
+)
+
+// propagationFlags are the mount flags of the propagations of bind mounts.
+var propagationFlags = map[string]int{
+	"shared":   syscall.MS_SHARED,
+	"rshared":  syscall.MS_SHARED | syscall.MS_REC,
+	"slave":    syscall.MS_SLAVE,
+	"rslave":   syscall.MS_SLAVE | syscall.MS_REC,
+	"private":  syscall.MS_PRIVATE,
+	"rprivate": syscall.MS_PRIVATE | syscall.MS_REC,
+}
+
+func init() {
+	reexec.Register(propagationHelperPath, propagationHelper)
+}
+
+// setupPropagationHelper points the propagation helper at the docker binary.
+func setupPropagationHelper(self string) error {
+	if err := os.Remove(propagationHelperPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Symlink(self, propagationHelperPath)
+}
+
+// setPropagation has the propagation of the mount applied once it is mounted
+// in the rootfs.
+func setPropagation(m *configs.Mount, rootfs, propagation string) error {
+	flags, ok := propagationFlags[propagation]
+	if !ok {
+		return fmt.Errorf("invalid propagation %s for the mount of %s", propagation, m.Destination)
+	}
+	m.PostmountCmds = append(m.PostmountCmds, configs.Command{
+		Path: propagationHelperPath,
+		Args: []string{rootfs, m.Destination, strconv.Itoa(flags)},
+	})
+	return nil
+}
+
+// setRootPropagation makes the mounts of the container rshared when one of
+// its mounts is shared.  libcontainer makes them rslave otherwise, and a mount
+// bind mounted from a slave never propagates back to the host whatever its
+// own propagation.  The rootfs is kept private and the mounts left outside of
+// it are made slaves before the container pivots to its rootfs.  It must be
+// called once all the mounts of the container are set up.
+func setRootPropagation(container *configs.Config, c *execdriver.Command) {
+	shared := false
+	for _, m := range c.Mounts {
+		if m.Propagation == "shared" || m.Propagation == "rshared" {
+			shared = true
+		}
+	}
+	if !shared || len(container.Mounts) == 0 {
+		return
+	}
+	container.RootPropagation = syscall.MS_SHARED | syscall.MS_REC
+	first := container.Mounts[0]
+	first.PremountCmds = append([]configs.Command{{
+		Path: propagationHelperPath,
+		Args: []string{privateRootfsArg, container.Rootfs},
+	}}, first.PremountCmds...)
+	last := container.Mounts[len(container.Mounts)-1]
+	last.PostmountCmds = append(last.PostmountCmds, configs.Command{
+		Path: propagationHelperPath,
+		Args: []string{slaveHostArg, container.Rootfs},
+	})
+}
+
+// propagationHelper changes the propagation of the mount at the destination
+// given in the rootfs.  The destination is resolved in the rootfs as
+// libcontainer did to mount it.
+func propagationHelper() {
+	if len(os.Args) == 3 {
+		rootfs, err := filepath.EvalSymlinks(os.Args[2])
+		if err != nil {
+			writeError(err)
+		}
+		switch os.Args[1] {
+		case privateRootfsArg:
+			err = syscall.Mount("", rootfs, "", syscall.MS_PRIVATE, "")
+		case slaveHostArg:
+			err = slaveHostMounts(rootfs)
+		default:
+			err = fmt.Errorf("unknown argument %s", os.Args[1])
+		}
+		if err != nil {
+			writeError(err)
+		}
+		return
+	}
+	if len(os.Args) != 4 {
+		writeError(fmt.Errorf("usage: %s <rootfs> <destination> <flags>", propagationHelperPath))
+	}
+	rootfs := os.Args[1]
+	flags, err := strconv.Atoi(os.Args[3])
+	if err != nil {
+		writeError(err)
+	}
+	dest, err := symlink.FollowSymlinkInScope(filepath.Join(rootfs, os.Args[2]), rootfs)
+	if err != nil {
+		writeError(err)
+	}
+	if err := syscall.Mount("", dest, "", uintptr(flags), ""); err != nil {
+		writeError(fmt.Errorf("cannot change the propagation of %s: %v", dest, err))
+	}
+}
+
+// slaveHostMounts makes the mounts outside of the rootfs slaves.  The mounts
+// of the container under the rootfs keep their propagation.
+func slaveHostMounts(rootfs string) error {
+	mounts, err := mount.GetMounts()
+	if err != nil {
+		return err
+	}
+	for _, m := range mounts {
+		if m.Mountpoint == rootfs || strings.HasPrefix(m.Mountpoint, rootfs+string(filepath.Separator)) {
+			continue
+		}
+		if err := syscall.Mount("", m.Mountpoint, "", syscall.MS_SLAVE, ""); err != nil {
+			return fmt.Errorf("cannot make %s a slave: %v", m.Mountpoint, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/libcontainer/system"
+)
+
+// restoredPollInterval is how often a restored container is checked for
+// exit.  It is not a child of the daemon anymore so it cannot be waited for.
+const restoredPollInterval = 500 * time.Millisecond
+
+// restoreContainers loads the containers left running by a previous instance
+// of the daemon, which libcontainer keeps the state of in the driver's root,
+// so that they are active again.  Containers that exited in the meantime are
+// left to be cleaned up by Terminate.
+func (d *driver) restoreContainers() {
+	dirs, err := ioutil.ReadDir(d.root)
+	if err != nil {
+		logrus.Warnf("Failed to restore running containers: %v", err)
+		return
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		id := dir.Name()
+		started := time.Now()
+		cont, err := d.loadContainer(id)
+		if err != nil {
+			// not a container, or a container that did not start
+			continue
+		}
+		state, err := cont.State()
+		if err != nil {
+			logrus.Debugf("Failed to restore container %s: %v", id, err)
+			continue
+		}
+		if !processAlive(state.InitProcessPid, state.InitProcessStartTime) {
+			continue
+		}
+		logrus.Debugf("Restored running container %s with pid %d", id, state.InitProcessPid)
+		d.activeContainers[id] = cont
+		d.restored[id] = true
+		d.metrics.restoreLatency.Observe(time.Since(started).Seconds())
+	}
+}
+
+// processAlive returns true if the process with the pid is still the one that
+// started at the given time and not a new process reusing the pid.
+func processAlive(pid int, startTime string) bool {
+	current, err := system.GetProcessStartTime(pid)
+	return err == nil && current == startTime
+}
+
+// Restored returns true if the container was left running by a previous
+// instance of the daemon and has not been reattached to yet.
+func (d *driver) Restored(id string) bool {
+	d.Lock()
+	defer d.Unlock()
+	return d.restored[id]
+}
+
+// Reattach waits for a restored container to exit.  The container's standard
+// streams were connected to the previous instance of the daemon and are not
+// reattached.  The exit code of the container is not known unless it was
+// killed through the driver, in which case it is reported as killed by the
+// signal, otherwise it is -1.
+func (d *driver) Reattach(c *execdriver.Command, startCallback execdriver.StartCallback) (execdriver.ExitStatus, error) {
+	d.Lock()
+	cont := d.activeContainers[c.ID]
+	restored := d.restored[c.ID]
+	delete(d.restored, c.ID)
+	d.Unlock()
+	if cont == nil || !restored {
+		return execdriver.ExitStatus{ExitCode: -1}, fmt.Errorf("container %s was not restored", c.ID)
+	}
+	defer func() {
+		cont.Destroy()
+		d.cleanContainer(c.ID)
+	}()
+
+	state, err := cont.State()
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	pid := state.InitProcessPid
+	if startCallback != nil {
+		startCallback(&c.ProcessConfig, pid)
+	}
+
+	var memoryCgroup string
+	if memoryCgroup, err = memoryCgroupName(state.CgroupPaths); err != nil {
+		logrus.Debugf("Failed to find memory cgroup of %s: %v", c.ID, err)
+	}
+	memoryHighDone := make(chan struct{})
+	if c.Resources != nil && c.Resources.MemoryHigh > 0 {
+		go d.throttleMemoryHigh(c, cont, memoryHighDone)
+	}
+	oom := d.watchOOM(c.ID, cont, memoryCgroup)
+
+	ticker := time.NewTicker(restoredPollInterval)
+	for processAlive(pid, state.InitProcessStartTime) {
+		<-ticker.C
+	}
+	ticker.Stop()
+	close(memoryHighDone)
+	if !ownsPidNamespace(cont.Config()) {
+		killCgroupProcs(cont)
+	}
+	cont.Destroy()
+	oomKill := <-oom
+	if oomKill {
+		d.metrics.oomKills.Inc("")
+	}
+
+	exitStatus := execdriver.ExitStatus{
+		ExitCode:  -1,
+		OOMKilled: oomKill,
+		Initiator: execdriver.InitiatorWorkload,
+	}
+	d.Lock()
+	req, ok := d.exitRequests[c.ID]
+	d.Unlock()
+	switch {
+	case oomKill:
+		exitStatus.Initiator = execdriver.InitiatorOOM
+		if memoryCgroup != "" {
+			if exitStatus.OOMReport, err = readOOMReport(memoryCgroup); err != nil {
+				logrus.Warnf("Failed to read the OOM report of %s: %v", c.ID, err)
+			}
+		}
+	case ok:
+		exitStatus.ExitCode = 128 + int(req.signal)
+		exitStatus.Signal = int(req.signal)
+		exitStatus.Initiator = req.initiator
+	default:
+		logrus.Infof("Restored container %s exited, its exit code is not known", c.ID)
+	}
+	return exitStatus, nil
+}
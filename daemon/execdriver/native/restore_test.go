@@ -0,0 +1,24 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"os"
+	"testing"
+
+	"github.com/docker/libcontainer/system"
+)
+
+func TestProcessAlive(t *testing.T) {
+	pid := os.Getpid()
+	startTime, err := system.GetProcessStartTime(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !processAlive(pid, startTime) {
+		t.Fatal("expected the test process to be alive")
+	}
+	if processAlive(pid, startTime+"0") {
+		t.Fatal("expected a process with another start time not to be alive")
+	}
+}
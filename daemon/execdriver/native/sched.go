@@ -0,0 +1,242 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/libcontainer"
+	"github.com/docker/libcontainer/cgroups"
+	"github.com/docker/libcontainer/configs"
+)
+
+// rtBudget is the real-time budget the driver added to an ancestor of the
+// cpu cgroups of the containers, by container.
+type rtBudget map[string]int64
+
+// rtCgroups wraps the cgroup managers of the factory so that the real-time
+// budget of the command is given to the cpu cgroup of the container as soon
+// as it is created, before the init of the container sets its scheduling
+// policy.  It must come after the option setting the cgroup manager.
+func (d *driver) rtCgroups(c *execdriver.Command) func(*libcontainer.LinuxFactory) error {
+	return func(l *libcontainer.LinuxFactory) error {
+		newManager := l.NewCgroupsManager
+		l.NewCgroupsManager = func(config *configs.Cgroup, paths map[string]string) cgroups.Manager {
+			return &rtManager{
+				Manager: newManager(config, paths),
+				driver:  d,
+				id:      c.ID,
+				period:  c.Resources.CpuRtPeriod,
+				runtime: c.Resources.CpuRtRuntime,
+			}
+		}
+		return nil
+	}
+}
+
+// needsRtBudget returns true if the container is given a real-time budget.
+func needsRtBudget(c *execdriver.Command) bool {
+	return c.RtPolicy != "" && c.Resources != nil && (c.Resources.CpuRtRuntime > 0 || c.Resources.CpuRtPeriod > 0)
+}
+
+// rtManager is a cgroup manager setting the real-time budget of the
+// container when its cgroups are applied.
+type rtManager struct {
+	cgroups.Manager
+	driver  *driver
+	id      string
+	period  int64
+	runtime int64
+}
+
+func (m *rtManager) Apply(pid int) error {
+	if err := m.Manager.Apply(pid); err != nil {
+		return err
+	}
+	path, ok := m.GetPaths()["cpu"]
+	if !ok {
+		return fmt.Errorf("cpu cgroup is not available for %s", m.id)
+	}
+	// the period goes first, the runtime is checked against it
+	if m.period > 0 {
+		if err := setRtPeriod(path, m.period); err != nil {
+			return err
+		}
+	}
+	if m.runtime > 0 {
+		mountpoint, err := cgroups.FindCgroupMountpoint("cpu")
+		if err != nil {
+			return err
+		}
+		return m.driver.setRtRuntime(m.id, mountpoint, path, m.runtime)
+	}
+	return nil
+}
+
+// setRtRuntime gives the cpu cgroup of the container a real-time budget and
+// keeps track of what its ancestors were raised by for it, which is released
+// with the container.
+func (d *driver) setRtRuntime(id, mountpoint, path string, runtime int64) error {
+	d.Lock()
+	defer d.Unlock()
+	raised, err := setRtRuntime(mountpoint, path, runtime)
+	for dir, amount := range raised {
+		if _, ok := d.rtBudgets[dir]; !ok {
+			d.rtBudgets[dir] = make(rtBudget)
+		}
+		d.rtBudgets[dir][id] += amount
+	}
+	return err
+}
+
+// releaseRtBudgets lowers the ancestors by the budget they were raised by for
+// the container, deepest first as a cgroup cannot have less budget than its
+// children.  The cgroup of the container must be removed.
+func (d *driver) releaseRtBudgets(id string) {
+	var dirs []string
+	for dir, b := range d.rtBudgets {
+		if _, ok := b[id]; ok {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(dirs)))
+	for _, dir := range dirs {
+		amount := d.rtBudgets[dir][id]
+		delete(d.rtBudgets[dir], id)
+		if len(d.rtBudgets[dir]) == 0 {
+			delete(d.rtBudgets, dir)
+		}
+		current, err := readRtRuntime(dir)
+		if err != nil {
+			logrus.Warnf("Failed to release the real-time runtime of %s: %v", dir, err)
+			continue
+		}
+		if current -= amount; current < 0 {
+			current = 0
+		}
+		if err := writeRtRuntime(dir, current); err != nil {
+			logrus.Warnf("Failed to release the real-time runtime of %s: %v", dir, err)
+		}
+	}
+}
+
+// setRtRuntime gives the cpu cgroup a real-time budget of runtime
+// microseconds per period.  The kernel requires every ancestor to have at
+// least the budget of all its children, so an ancestor short of it is raised
+// to the budget of its other children plus the new one, which in turn may
+// need its own parent raised.  The ancestors are raised from the top.  It
+// returns what each raised ancestor was raised by.  It is a no-op on kernels
+// without CONFIG_RT_GROUP_SCHED where real-time tasks are not limited per
+// cgroup.
+func setRtRuntime(mountpoint, path string, runtime int64) (map[string]int64, error) {
+	rel, err := filepath.Rel(mountpoint, path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(path, "cpu.rt_runtime_us")); os.IsNotExist(err) {
+		return nil, nil
+	}
+	var ancestors []string
+	dir := mountpoint
+	for _, name := range strings.Split(rel, string(filepath.Separator)) {
+		dir = filepath.Join(dir, name)
+		if dir != path {
+			ancestors = append(ancestors, dir)
+		}
+	}
+
+	// from the bottom, the budget each ancestor needs for its children
+	needed := make(map[string]int64)
+	child, budget := path, runtime
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		dir := ancestors[i]
+		current, err := readRtRuntime(dir)
+		if err != nil {
+			return nil, err
+		}
+		others, err := childrenRtRuntime(dir, child)
+		if err != nil {
+			return nil, err
+		}
+		if current >= others+budget {
+			break
+		}
+		needed[dir] = others + budget - current
+		child, budget = dir, others+budget
+	}
+
+	raised := make(map[string]int64)
+	for _, dir := range ancestors {
+		amount, ok := needed[dir]
+		if !ok {
+			continue
+		}
+		current, err := readRtRuntime(dir)
+		if err != nil {
+			return raised, err
+		}
+		if err := writeRtRuntime(dir, current+amount); err != nil {
+			return raised, err
+		}
+		raised[dir] = amount
+	}
+	return raised, writeRtRuntime(path, runtime)
+}
+
+// childrenRtRuntime returns the real-time budget given to the children of the
+// cpu cgroup but the excluded one.
+func childrenRtRuntime(dir, exclude string) (int64, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, info := range infos {
+		child := filepath.Join(dir, info.Name())
+		if !info.IsDir() || child == exclude {
+			continue
+		}
+		runtime, err := readRtRuntime(child)
+		if err != nil {
+			return 0, err
+		}
+		total += runtime
+	}
+	return total, nil
+}
+
+func readRtRuntime(dir string) (int64, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, "cpu.rt_runtime_us"))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
+
+func writeRtRuntime(dir string, runtime int64) error {
+	if err := ioutil.WriteFile(filepath.Join(dir, "cpu.rt_runtime_us"), []byte(strconv.FormatInt(runtime, 10)), 0644); err != nil {
+		return fmt.Errorf("set real-time runtime of %s: %v", dir, err)
+	}
+	return nil
+}
+
+// setRtPeriod sets the period of the real-time budget of the cpu cgroup.  Like
+// setRtRuntime it is a no-op on kernels without CONFIG_RT_GROUP_SCHED.
+func setRtPeriod(path string, period int64) error {
+	file := filepath.Join(path, "cpu.rt_period_us")
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return nil
+	}
+	if err := ioutil.WriteFile(file, []byte(strconv.FormatInt(period, 10)), 0644); err != nil {
+		return fmt.Errorf("set real-time period of %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"fmt"
+	"syscall"
+
+	"github.com/docker/docker/daemon/execdriver"
+)
+
+// SignalProcess sends the signal to a process of the container, such as an
+// exec'd process.  The pid is checked against the processes in the
+// container's cgroup so that no process outside of the container is signaled.
+func (d *driver) SignalProcess(id string, pid int, sig syscall.Signal) error {
+	d.Lock()
+	active := d.activeContainers[id]
+	d.Unlock()
+	if active == nil {
+		return &execdriver.ErrContainerNotActive{ID: id}
+	}
+	pids, err := active.Processes()
+	if err != nil {
+		return &execdriver.ErrCgroupFailure{ID: id, Err: err}
+	}
+	for _, p := range pids {
+		if p == pid {
+			return syscall.Kill(pid, sig)
+		}
+	}
+	return fmt.Errorf("No such process %d in container %s", pid, id)
+}
@@ -0,0 +1,169 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/libcontainer"
+)
+
+// statsStream collects the stats of a container at an interval and sends
+// them to its subscribers.
+type statsStream struct {
+	subscribers map[chan *execdriver.ResourceStats]struct{}
+	done        chan struct{}
+}
+
+// StatsStream returns a channel receiving the stats of the container every
+// interval and a function that stops the stream.  Subscribers with the same
+// interval share a single collection.  The channel is closed when the
+// container exits or the stream is stopped.  Stats are dropped if the
+// subscriber does not keep up.
+func (d *driver) StatsStream(id string, interval time.Duration) (<-chan *execdriver.ResourceStats, func(), error) {
+	if interval <= 0 {
+		return nil, nil, fmt.Errorf("invalid stats interval %v", interval)
+	}
+	d.Lock()
+	defer d.Unlock()
+	if d.activeContainers[id] == nil {
+		return nil, nil, execdriver.ErrNotRunning
+	}
+	streams := d.statsStreams[id]
+	if streams == nil {
+		streams = make(map[time.Duration]*statsStream)
+		d.statsStreams[id] = streams
+	}
+	s := streams[interval]
+	if s == nil {
+		s = &statsStream{
+			subscribers: make(map[chan *execdriver.ResourceStats]struct{}),
+			done:        make(chan struct{}),
+		}
+		streams[interval] = s
+		go d.collectStats(id, interval, s)
+	}
+	ch := make(chan *execdriver.ResourceStats, 1)
+	s.subscribers[ch] = struct{}{}
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			d.Lock()
+			defer d.Unlock()
+			if _, ok := s.subscribers[ch]; !ok {
+				// closed when the container exited
+				return
+			}
+			delete(s.subscribers, ch)
+			close(ch)
+			if len(s.subscribers) == 0 {
+				close(s.done)
+				delete(d.statsStreams[id], interval)
+			}
+		})
+	}
+	return ch, stop, nil
+}
+
+func (d *driver) collectStats(id string, interval time.Duration, s *statsStream) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+		stats, err := d.Stats(id)
+		if err != nil {
+			if err != execdriver.ErrNotRunning {
+				logrus.Errorf("collecting stats for %s: %v", id, err)
+			}
+			continue
+		}
+		d.Lock()
+		for ch := range s.subscribers {
+			select {
+			case ch <- stats:
+			default:
+			}
+		}
+		d.Unlock()
+	}
+}
+
+// statsWorkers is the number of containers whose stats are collected at
+// the same time by StatsAll.
+const statsWorkers = 16
+
+// StatsAll returns the stats of all running containers.  The containers are
+// looked up at once and their stats collected concurrently.  Containers that
+// exit in the meantime are left out.
+func (d *driver) StatsAll() (map[string]*execdriver.ResourceStats, error) {
+	d.Lock()
+	active := make(map[string]libcontainer.Container, len(d.activeContainers))
+	for id, c := range d.activeContainers {
+		active[id] = c
+	}
+	d.Unlock()
+
+	type result struct {
+		id    string
+		stats *execdriver.ResourceStats
+	}
+	var (
+		ids     = make(chan string)
+		results = make(chan result)
+		wg      sync.WaitGroup
+	)
+	workers := statsWorkers
+	if len(active) < workers {
+		workers = len(active)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range ids {
+				stats, err := d.containerStats(id, active[id])
+				if err != nil {
+					logrus.Debugf("collecting stats for %s: %v", id, err)
+					continue
+				}
+				results <- result{id, stats}
+			}
+		}()
+	}
+	go func() {
+		for id := range active {
+			ids <- id
+		}
+		close(ids)
+		wg.Wait()
+		close(results)
+	}()
+
+	all := make(map[string]*execdriver.ResourceStats, len(active))
+	for r := range results {
+		all[r.id] = r.stats
+	}
+	return all, nil
+}
+
+// closeStatsStreams stops the stats streams of the container and closes the
+// channels of their subscribers.  It must be called with the lock held.
+func (d *driver) closeStatsStreams(id string) {
+	for _, s := range d.statsStreams[id] {
+		for ch := range s.subscribers {
+			close(ch)
+		}
+		s.subscribers = nil
+		close(s.done)
+	}
+	delete(d.statsStreams, id)
+}
@@ -0,0 +1,148 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/libcontainer"
+	"github.com/docker/libcontainer/configs"
+)
+
+// statsContainer is a running container with empty stats.
+type statsContainer struct {
+	libcontainer.Container
+}
+
+func (c *statsContainer) Stats() (*libcontainer.Stats, error) {
+	return &libcontainer.Stats{}, nil
+}
+
+func (c *statsContainer) Config() configs.Config {
+	return configs.Config{Cgroups: &configs.Cgroup{}}
+}
+
+func (c *statsContainer) State() (*libcontainer.State, error) {
+	return nil, errors.New("no state")
+}
+
+func newStatsDriver(ids ...string) *driver {
+	d := &driver{
+		activeContainers: make(map[string]libcontainer.Container),
+		statsStreams:     make(map[string]map[time.Duration]*statsStream),
+	}
+	for _, id := range ids {
+		d.activeContainers[id] = &statsContainer{}
+	}
+	return d
+}
+
+func receiveStats(t *testing.T, ch <-chan *execdriver.ResourceStats) *execdriver.ResourceStats {
+	select {
+	case stats := <-ch:
+		return stats
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for stats")
+	}
+	return nil
+}
+
+func TestStatsStreamFanOut(t *testing.T) {
+	d := newStatsDriver("web")
+	ch1, stop1, err := d.StatsStream("web", 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch2, stop2, err := d.StatsStream("web", 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop2()
+	if len(d.statsStreams["web"]) != 1 {
+		t.Fatalf("expected the subscribers to share a stream, got %d", len(d.statsStreams["web"]))
+	}
+	if stats := receiveStats(t, ch1); stats == nil || stats.Stats == nil {
+		t.Fatalf("expected stats, got %v", stats)
+	}
+	receiveStats(t, ch2)
+
+	stop1()
+	// stopping twice is harmless
+	stop1()
+	for range ch1 {
+	}
+	// the other subscriber keeps receiving
+	receiveStats(t, ch2)
+}
+
+func TestStatsStreamStop(t *testing.T) {
+	d := newStatsDriver("web")
+	ch, stop, err := d.StatsStream("web", 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop()
+	for range ch {
+	}
+	d.Lock()
+	defer d.Unlock()
+	if len(d.statsStreams["web"]) != 0 {
+		t.Fatal("expected the stream removed once it has no subscribers")
+	}
+}
+
+func TestStatsStreamCloseOnExit(t *testing.T) {
+	d := newStatsDriver("web")
+	ch, stop, err := d.StatsStream("web", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Lock()
+	d.closeStatsStreams("web")
+	d.Unlock()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("expected no stats after the container exited")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the channel closed when the container exits")
+	}
+	// stopping after the exit is harmless
+	stop()
+
+	if _, _, err := d.StatsStream("db", time.Second); err != execdriver.ErrNotRunning {
+		t.Fatalf("expected ErrNotRunning for a container that is not running, got %v", err)
+	}
+}
+
+// exitedContainer is a container that exited while its stats were being
+// collected.
+type exitedContainer struct {
+	libcontainer.Container
+}
+
+func (c *exitedContainer) Stats() (*libcontainer.Stats, error) {
+	return nil, errors.New("container exited")
+}
+
+func TestStatsAllExitedContainers(t *testing.T) {
+	d := newStatsDriver("web", "db")
+	d.activeContainers["cache"] = &exitedContainer{}
+	all, err := d.StatsAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all["web"] == nil || all["db"] == nil {
+		t.Fatalf("expected the stats of the running containers only, got %v", all)
+	}
+
+	d = newStatsDriver()
+	d.activeContainers["cache"] = &exitedContainer{}
+	if all, err := d.StatsAll(); err != nil || len(all) != 0 {
+		t.Fatalf("expected no stats once all containers exited, got %v: %v", all, err)
+	}
+}
@@ -0,0 +1,160 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/docker/docker/daemon/execdriver"
+)
+
+// defaultStdioBuffer is the size of the buffer copying the stdio of
+// containers, the same as io.Copy's.
+const defaultStdioBuffer = 32 * 1024
+
+// stdio accounts the bytes copied between the stdio of a container and its
+// clients and limits the rate of its output.  Exec commands share the stdio
+// of their container.
+type stdio struct {
+	stdin, stdout, stderr uint64 // updated atomically
+	bufferSize            int
+	limiter               *rateLimiter // nil when the output is not limited
+}
+
+func newStdio(bufferSize int, rate int64) *stdio {
+	s := &stdio{bufferSize: bufferSize}
+	if rate > 0 {
+		s.limiter = &rateLimiter{rate: rate}
+	}
+	return s
+}
+
+// wrap returns pipes copying through s.  Writes to stdout and stderr block
+// while the output is above the rate, which in turn blocks the container
+// writing to them.
+func (s *stdio) wrap(pipes *execdriver.Pipes) *execdriver.Pipes {
+	wrapped := &execdriver.Pipes{}
+	if pipes.Stdout != nil {
+		wrapped.Stdout = &stdioWriter{w: pipes.Stdout, count: &s.stdout, stdio: s}
+	}
+	if pipes.Stderr != nil {
+		wrapped.Stderr = &stdioWriter{w: pipes.Stderr, count: &s.stderr, stdio: s}
+	}
+	if pipes.Stdin != nil {
+		wrapped.Stdin = &stdioReader{r: pipes.Stdin, count: &s.stdin, stdio: s}
+	}
+	return wrapped
+}
+
+func (s *stdio) stats() execdriver.StdioStats {
+	return execdriver.StdioStats{
+		StdinBytes:  atomic.LoadUint64(&s.stdin),
+		StdoutBytes: atomic.LoadUint64(&s.stdout),
+		StderrBytes: atomic.LoadUint64(&s.stderr),
+	}
+}
+
+// stdioWriter counts and limits the output of a container.  It implements
+// io.ReaderFrom so that io.Copy uses the buffer size of the stdio.
+type stdioWriter struct {
+	w     io.Writer
+	count *uint64
+	stdio *stdio
+}
+
+func (s *stdioWriter) Write(p []byte) (int, error) {
+	if s.stdio.limiter != nil {
+		s.stdio.limiter.wait(len(p))
+	}
+	n, err := s.w.Write(p)
+	atomic.AddUint64(s.count, uint64(n))
+	return n, err
+}
+
+func (s *stdioWriter) ReadFrom(r io.Reader) (int64, error) {
+	return copyBuffer(s, r, s.stdio.bufferSize)
+}
+
+// CloseWriters closes the writers of the output when it is a broadcaster.
+func (s *stdioWriter) CloseWriters() error {
+	if wb, ok := s.w.(interface {
+		CloseWriters() error
+	}); ok {
+		return wb.CloseWriters()
+	}
+	return nil
+}
+
+// stdioReader counts the input of a container.  It implements io.WriterTo so
+// that io.Copy uses the buffer size of the stdio.
+type stdioReader struct {
+	r     io.ReadCloser
+	count *uint64
+	stdio *stdio
+}
+
+func (s *stdioReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	atomic.AddUint64(s.count, uint64(n))
+	return n, err
+}
+
+func (s *stdioReader) WriteTo(w io.Writer) (int64, error) {
+	return copyBuffer(w, s, s.stdio.bufferSize)
+}
+
+func (s *stdioReader) Close() error {
+	return s.r.Close()
+}
+
+// copyBuffer is io.Copy with a buffer of the given size.  The source and the
+// destination are not checked for io.WriterTo and io.ReaderFrom so that it
+// can implement them.
+func copyBuffer(dst io.Writer, src io.Reader, size int) (int64, error) {
+	var (
+		written int64
+		buf     = make([]byte, size)
+	)
+	for {
+		nr, er := src.Read(buf)
+		if nr > 0 {
+			nw, ew := dst.Write(buf[:nr])
+			written += int64(nw)
+			if ew != nil {
+				return written, ew
+			}
+			if nw != nr {
+				return written, io.ErrShortWrite
+			}
+		}
+		if er == io.EOF {
+			return written, nil
+		}
+		if er != nil {
+			return written, er
+		}
+	}
+}
+
+// rateLimiter limits a stream to rate bytes per second.
+type rateLimiter struct {
+	rate int64
+	mu   sync.Mutex
+	next time.Time // when the bytes let through so far are within the rate
+}
+
+// wait blocks until n bytes may be let through.
+func (l *rateLimiter) wait(n int) {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(int64(n) * int64(time.Second) / l.rate))
+	l.mu.Unlock()
+	time.Sleep(delay)
+}
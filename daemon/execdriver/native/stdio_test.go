@@ -0,0 +1,52 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/execdriver"
+)
+
+func TestStdioCounts(t *testing.T) {
+	s := newStdio(512, 0)
+	var stdout, stderr bytes.Buffer
+	pipes := s.wrap(&execdriver.Pipes{
+		Stdin:  ioutil.NopCloser(strings.NewReader("input")),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if _, err := io.Copy(ioutil.Discard, pipes.Stdin); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(pipes.Stdout, strings.NewReader(strings.Repeat("x", 2000))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pipes.Stderr.Write([]byte("error")); err != nil {
+		t.Fatal(err)
+	}
+	expected := execdriver.StdioStats{StdinBytes: 5, StdoutBytes: 2000, StderrBytes: 5}
+	if got := s.stats(); got != expected {
+		t.Fatalf("expected %+v, got %+v", expected, got)
+	}
+	if stdout.Len() != 2000 || stderr.String() != "error" {
+		t.Fatalf("unexpected output %d bytes of stdout, %q on stderr", stdout.Len(), stderr.String())
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	l := &rateLimiter{rate: 10000}
+	start := time.Now()
+	// the first write goes through, the next ones wait for it
+	for i := 0; i < 3; i++ {
+		l.wait(1000)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("expected 3000 bytes at 10000 bytes per second to take at least 200ms, took %v", elapsed)
+	}
+}
@@ -0,0 +1,67 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"syscall"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/libcontainer"
+)
+
+// Stop sends stopSignal to the init process of the container and, if the
+// container has not exited after timeout, kills it and whatever is left in
+// its cgroup.
+func (d *driver) Stop(c *execdriver.Command, timeout time.Duration, stopSignal syscall.Signal) error {
+	d.Lock()
+	active := d.activeContainers[c.ID]
+	if active != nil {
+		d.exitRequests[c.ID] = exitRequest{execdriver.InitiatorStop, stopSignal}
+	}
+	d.Unlock()
+	if active == nil {
+		return &execdriver.ErrContainerNotActive{ID: c.ID}
+	}
+	state, err := active.State()
+	if err != nil {
+		return err
+	}
+	pid := state.InitProcessPid
+	if err := signalInit(c.ID, active, pid, stopSignal); err != nil && err != syscall.ESRCH {
+		return err
+	}
+	if d.waitExit(c.ID, active, timeout) {
+		return nil
+	}
+
+	logrus.Infof("Container %s failed to exit within %s of signal %d - using the force", c.ID, timeout, stopSignal)
+	d.Lock()
+	d.exitRequests[c.ID] = exitRequest{execdriver.InitiatorStop, syscall.SIGKILL}
+	d.Unlock()
+	if err := signalInit(c.ID, active, pid, syscall.SIGKILL); err != nil && err != syscall.ESRCH {
+		return err
+	}
+	// processes outside of the container's pid namespace survive init
+	killCgroupProcs(active)
+	return nil
+}
+
+// waitExit waits up to timeout for the container to exit and returns whether
+// it did.
+func (d *driver) waitExit(id string, active libcontainer.Container, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		d.Lock()
+		current := d.activeContainers[id]
+		d.Unlock()
+		if current != active {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -0,0 +1,52 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/libcontainer/configs"
+)
+
+// Update changes the cgroup limits of a running container to the given
+// resources.  The rlimits of the container cannot be changed while it runs
+// and are ignored.
+func (d *driver) Update(id string, resources *execdriver.Resources) error {
+	d.Lock()
+	active := d.activeContainers[id]
+	d.Unlock()
+	if active == nil {
+		return &execdriver.ErrContainerNotActive{ID: id}
+	}
+	config := active.Config()
+	// the cgroup config is shared with the running container, copy it
+	cgroup := *config.Cgroups
+	config.Cgroups = &cgroup
+	if err := execdriver.SetupCgroups(&config, &execdriver.Command{Resources: resources}); err != nil {
+		return err
+	}
+	if err := active.Set(config); err != nil {
+		return &execdriver.ErrCgroupFailure{ID: id, Err: err}
+	}
+	d.notifyMemoryHigh(id)
+	return nil
+}
+
+// AddDevice allows the running container to use the device with the cgroup
+// permissions of dev.  Only the devices cgroup is changed, the device node is
+// not created in the container.
+func (d *driver) AddDevice(id string, dev *configs.Device) error {
+	d.Lock()
+	active := d.activeContainers[id]
+	d.Unlock()
+	if active == nil {
+		return &execdriver.ErrContainerNotActive{ID: id}
+	}
+	config := active.Config()
+	cgroup := *config.Cgroups
+	cgroup.AllowedDevices = append(append([]*configs.Device(nil), cgroup.AllowedDevices...), dev)
+	config.Cgroups = &cgroup
+	if err := active.Set(config); err != nil {
+		return &execdriver.ErrCgroupFailure{ID: id, Err: err}
+	}
+	return nil
+}
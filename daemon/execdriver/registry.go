@@ -0,0 +1,50 @@
+package execdriver
+
+import (
+	"fmt"
+	"sort"
+)
+
+// InitFunc creates a driver from the configuration of the daemon.
+type InitFunc func(config *DriverConfig) (Driver, error)
+
+// DriverConfig is the configuration of the daemon given to the drivers.
+type DriverConfig struct {
+	Root     string   // directory where drivers keep their state
+	LibPath  string   // root of the daemon
+	InitPath string   // path of dockerinit
+	Options  []string // exec options, each driver picks its own
+	AppArmor bool     // apparmor is enabled on the host
+}
+
+// All registered drivers
+var drivers = make(map[string]InitFunc)
+
+// Register makes a driver available by name.  Drivers register themselves
+// when their package is imported.
+func Register(name string, initFunc InitFunc) error {
+	if _, exists := drivers[name]; exists {
+		return fmt.Errorf("Name already registered %s", name)
+	}
+	drivers[name] = initFunc
+	return nil
+}
+
+// GetDriver creates the driver registered with the name.
+func GetDriver(name string, config *DriverConfig) (Driver, error) {
+	initFunc, exists := drivers[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown exec driver %s", name)
+	}
+	return initFunc(config)
+}
+
+// Drivers returns the names of the registered drivers.
+func Drivers() []string {
+	var names []string
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
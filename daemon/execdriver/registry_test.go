@@ -0,0 +1,26 @@
+package execdriver
+
+import "testing"
+
+func TestRegister(t *testing.T) {
+	initFunc := func(config *DriverConfig) (Driver, error) {
+		return nil, nil
+	}
+	if err := Register("test", initFunc); err != nil {
+		t.Fatal(err)
+	}
+	defer delete(drivers, "test")
+	if err := Register("test", initFunc); err == nil {
+		t.Fatal("expected an error registering test twice")
+	}
+	found := false
+	for _, name := range Drivers() {
+		found = found || name == "test"
+	}
+	if !found {
+		t.Fatalf("expected test in %v", Drivers())
+	}
+	if _, err := GetDriver("unknown", &DriverConfig{}); err == nil {
+		t.Fatal("expected an error for an unknown driver")
+	}
+}
@@ -0,0 +1,258 @@
+package execdriver
+
+import (
+	"fmt"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Names of the restart policies.
+const (
+	RestartPolicyNo            = "no"
+	RestartPolicyAlways        = "always"
+	RestartPolicyOnFailure     = "on-failure"
+	RestartPolicyUnlessStopped = "unless-stopped"
+)
+
+const (
+	// defaultRestartDelay is the delay before the first restart, it doubles
+	// on each restart of a container that exits quickly.
+	defaultRestartDelay = 100 * time.Millisecond
+	// restartDelayReset is how long a container must run for the delay
+	// before its next restart to go back to the default.
+	restartDelayReset = 10 * time.Second
+)
+
+// RestartPolicy tells a Supervisor when to run a container again after it
+// exits.  A container is never restarted after it was asked to stop through
+// the Supervisor, so unless-stopped restarts it like always.
+type RestartPolicy struct {
+	Name              string // one of the RestartPolicy names, never restarted if empty
+	MaximumRetryCount int    // restarts after failures in a row for on-failure, unlimited if 0
+}
+
+// SupervisorHooks are called by a Supervisor around each run of a container.
+type SupervisorHooks struct {
+	// Prepare is called before each run with the number of times the
+	// container was restarted and returns the pipes of the run.  An error
+	// ends the supervision.
+	Prepare func(restartCount int) (*Pipes, error)
+	// Started is the start callback of each run.
+	Started StartCallback
+	// Exited is called after each run with its exit status and error,
+	// restart is true if the container is run again.
+	Exited func(exitStatus ExitStatus, err error, restart bool)
+}
+
+// Supervisor runs a container with a driver and runs it again when it exits
+// according to its restart policy, waiting twice as long before each restart
+// of a container that keeps exiting quickly.  The container is stopped
+// through the Supervisor so that the decision to restart it cannot race with
+// the request to stop it.
+type Supervisor struct {
+	driver Driver
+
+	mu      sync.Mutex
+	command *Command
+	policy  RestartPolicy
+	// stopped is set once the container is asked to stop, it is not
+	// restarted anymore
+	stopped bool
+	// stopChan is closed when stopped is set to interrupt the wait before a
+	// restart
+	stopChan chan struct{}
+	// restarting is set from the decision to restart the container until
+	// the next run has started
+	restarting   bool
+	failureCount int
+	delay        time.Duration
+	lastStart    time.Time
+}
+
+// NewSupervisor returns a Supervisor running containers with the driver.
+func NewSupervisor(d Driver) *Supervisor {
+	return &Supervisor{
+		driver:   d,
+		stopChan: make(chan struct{}),
+		delay:    defaultRestartDelay,
+	}
+}
+
+// RunSupervised runs the container until it exits without being restarted
+// and returns the exit status of its last run.  An error running the
+// container the first time is returned right away, without calling Exited.
+func (s *Supervisor) RunSupervised(c *Command, policy RestartPolicy, hooks SupervisorHooks) (ExitStatus, error) {
+	s.mu.Lock()
+	s.command = c
+	s.policy = policy
+	s.mu.Unlock()
+
+	startCallback := func(processConfig *ProcessConfig, pid int) {
+		if hooks.Started != nil {
+			hooks.Started(processConfig, pid)
+		}
+		s.mu.Lock()
+		// the container was asked to stop while it was restarting, the
+		// request could not be sent to it then
+		kill := s.restarting && s.stopped
+		s.restarting = false
+		s.mu.Unlock()
+		if kill {
+			s.driver.Kill(c, int(syscall.SIGKILL))
+		}
+	}
+
+	var exitStatus ExitStatus
+	for restartCount := 0; ; restartCount++ {
+		pipes, err := hooks.Prepare(restartCount)
+		if err != nil {
+			s.mu.Lock()
+			s.restarting = false
+			s.mu.Unlock()
+			return exitStatus, err
+		}
+
+		s.mu.Lock()
+		s.lastStart = time.Now()
+		s.mu.Unlock()
+
+		exitStatus, err = s.driver.Run(c, pipes, startCallback)
+		if err != nil && restartCount == 0 {
+			return exitStatus, err
+		}
+
+		restart := s.exited(exitStatus, err)
+		if hooks.Exited != nil {
+			hooks.Exited(exitStatus, err, restart)
+		}
+		if !restart || !s.waitForRestart() {
+			return exitStatus, err
+		}
+	}
+}
+
+// Reattach waits for a container left running by a previous instance of the
+// daemon to exit and returns whether it must be restarted.  The container
+// is started again by the caller, the driver no longer has its command.
+func (s *Supervisor) Reattach(c *Command, policy RestartPolicy, startedAt time.Time) (ExitStatus, bool, error) {
+	r, ok := s.driver.(Reattacher)
+	if !ok {
+		return ExitStatus{ExitCode: -1}, false, fmt.Errorf("%s does not reattach to containers", s.driver.Name())
+	}
+	s.mu.Lock()
+	s.command = c
+	s.policy = policy
+	s.lastStart = startedAt
+	s.mu.Unlock()
+
+	exitStatus, err := r.Reattach(c, nil)
+	return exitStatus, s.exited(exitStatus, err), err
+}
+
+// ExitOnNext tells the Supervisor not to restart the container when it next
+// exits and ends the wait before a restart.  It returns true if the container
+// is restarting, in which case it is stopped without being sent a signal.
+func (s *Supervisor) ExitOnNext() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// stop may be requested several times, the channel is closed once
+	if !s.stopped {
+		s.stopped = true
+		close(s.stopChan)
+	}
+	return s.restarting
+}
+
+// Kill stops supervising the container and sends it the signal.
+func (s *Supervisor) Kill(sig int) error {
+	if s.ExitOnNext() {
+		return nil
+	}
+	return s.driver.Kill(s.runningCommand(), sig)
+}
+
+// Stop stops supervising the container and lets the driver stop it, the
+// driver must implement Stopper.
+func (s *Supervisor) Stop(timeout time.Duration, stopSignal syscall.Signal) error {
+	stopper, ok := s.driver.(Stopper)
+	if !ok {
+		return fmt.Errorf("%s does not stop containers", s.driver.Name())
+	}
+	if s.ExitOnNext() {
+		return nil
+	}
+	return stopper.Stop(s.runningCommand(), timeout, stopSignal)
+}
+
+// Terminate stops supervising the container and kills it with fire.
+func (s *Supervisor) Terminate() error {
+	if s.ExitOnNext() {
+		return nil
+	}
+	return s.driver.Terminate(s.runningCommand())
+}
+
+func (s *Supervisor) runningCommand() *Command {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.command
+}
+
+// exited updates the failure count and the delay before the next restart
+// after a run of the container and returns whether it must be restarted.
+func (s *Supervisor) exited(exitStatus ExitStatus, err error) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.lastStart) > restartDelayReset {
+		s.delay = defaultRestartDelay
+	} else {
+		s.delay *= 2
+	}
+	if err == nil && exitStatus.ExitCode == 0 {
+		s.failureCount = 0
+	} else {
+		s.failureCount++
+	}
+
+	s.restarting = !s.stopped && s.shouldRestart(exitStatus.ExitCode)
+	return s.restarting
+}
+
+// shouldRestart applies the restart policy to the exit code of the
+// container.
+func (s *Supervisor) shouldRestart(exitCode int) bool {
+	switch s.policy.Name {
+	case RestartPolicyAlways, RestartPolicyUnlessStopped:
+		return true
+	case RestartPolicyOnFailure:
+		if max := s.policy.MaximumRetryCount; max != 0 && s.failureCount > max {
+			return false
+		}
+		return exitCode != 0
+	}
+	return false
+}
+
+// waitForRestart waits for the delay before restarting the container and
+// returns false if it was asked to stop in the meantime.
+func (s *Supervisor) waitForRestart() bool {
+	s.mu.Lock()
+	delay, stopChan := s.delay, s.stopChan
+	s.mu.Unlock()
+
+	select {
+	case <-time.After(delay):
+	case <-stopChan:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		s.restarting = false
+		return false
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+package execdriver
+
+import (
+	"syscall"
+	"testing"
+)
+
+// runDriver runs containers that exit with the exit codes in turn, the last
+// one once they are all used.
+type runDriver struct {
+	Driver
+	exitCodes []int
+	runs      int
+	kills     []int
+}
+
+func (d *runDriver) Run(c *Command, pipes *Pipes, startCallback StartCallback) (ExitStatus, error) {
+	startCallback(&c.ProcessConfig, 1)
+	exitCode := d.exitCodes[len(d.exitCodes)-1]
+	if d.runs < len(d.exitCodes) {
+		exitCode = d.exitCodes[d.runs]
+	}
+	d.runs++
+	return ExitStatus{ExitCode: exitCode}, nil
+}
+
+func (d *runDriver) Kill(c *Command, sig int) error {
+	d.kills = append(d.kills, sig)
+	return nil
+}
+
+func runSupervised(s *Supervisor, policy RestartPolicy, hooks SupervisorHooks) ([]bool, ExitStatus, error) {
+	var restarts []bool
+	prepare := hooks.Prepare
+	hooks.Prepare = func(restartCount int) (*Pipes, error) {
+		if prepare != nil {
+			return prepare(restartCount)
+		}
+		return &Pipes{}, nil
+	}
+	exited := hooks.Exited
+	hooks.Exited = func(exitStatus ExitStatus, err error, restart bool) {
+		restarts = append(restarts, restart)
+		if exited != nil {
+			exited(exitStatus, err, restart)
+		}
+	}
+	exitStatus, err := s.RunSupervised(&Command{ID: "test"}, policy, hooks)
+	return restarts, exitStatus, err
+}
+
+func TestSupervisorRestartPolicies(t *testing.T) {
+	tests := []struct {
+		policy    RestartPolicy
+		exitCodes []int
+		runs      int
+	}{
+		{RestartPolicy{Name: RestartPolicyNo}, []int{1}, 1},
+		{RestartPolicy{Name: RestartPolicyOnFailure}, []int{1, 1, 0}, 3},
+		{RestartPolicy{Name: RestartPolicyOnFailure, MaximumRetryCount: 1}, []int{1, 1, 1}, 2},
+		{RestartPolicy{Name: RestartPolicyAlways}, []int{0, 1, 0}, 3},
+	}
+	for _, test := range tests {
+		d := &runDriver{exitCodes: test.exitCodes}
+		s := NewSupervisor(d)
+		if test.policy.Name == RestartPolicyAlways {
+			// always restarts forever, stop once the container ran enough
+			d.exitCodes = append(d.exitCodes, -1)
+		}
+		hooks := SupervisorHooks{
+			Exited: func(exitStatus ExitStatus, err error, restart bool) {
+				if exitStatus.ExitCode == -1 {
+					t.Fatalf("%s: container ran too many times", test.policy.Name)
+				}
+				if d.runs == test.runs {
+					s.ExitOnNext()
+				}
+			},
+		}
+		restarts, exitStatus, err := runSupervised(s, test.policy, hooks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.runs != test.runs {
+			t.Fatalf("%s: expected %d runs got %d", test.policy.Name, test.runs, d.runs)
+		}
+		if exitStatus.ExitCode != test.exitCodes[test.runs-1] {
+			t.Fatalf("%s: expected exit code %d got %d", test.policy.Name, test.exitCodes[test.runs-1], exitStatus.ExitCode)
+		}
+		for i, restart := range restarts {
+			if restart != (i < test.runs-1) && test.policy.Name != RestartPolicyAlways {
+				t.Fatalf("%s: unexpected restart %v after run %d", test.policy.Name, restart, i+1)
+			}
+		}
+	}
+}
+
+func TestSupervisorKillWhileRestarting(t *testing.T) {
+	d := &runDriver{exitCodes: []int{1}}
+	s := NewSupervisor(d)
+	hooks := SupervisorHooks{
+		Exited: func(exitStatus ExitStatus, err error, restart bool) {
+			if !restart {
+				t.Fatal("expected the container to be restarted")
+			}
+			if err := s.Kill(int(syscall.SIGTERM)); err != nil {
+				t.Fatal(err)
+			}
+		},
+	}
+	restarts, _, err := runSupervised(s, RestartPolicy{Name: RestartPolicyAlways}, hooks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.runs != 1 || len(restarts) != 1 {
+		t.Fatalf("expected the restart to be cancelled, ran %d times", d.runs)
+	}
+	if len(d.kills) != 0 {
+		t.Fatalf("expected no signal sent to a restarting container got %v", d.kills)
+	}
+}
+
+func TestSupervisorKillBeforeRestartStarts(t *testing.T) {
+	d := &runDriver{exitCodes: []int{1}}
+	s := NewSupervisor(d)
+	hooks := SupervisorHooks{
+		Prepare: func(restartCount int) (*Pipes, error) {
+			// the wait before the restart is over, the container is
+			// already being run again
+			if restartCount == 1 {
+				if err := s.Kill(int(syscall.SIGTERM)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			return &Pipes{}, nil
+		},
+	}
+	restarts, _, err := runSupervised(s, RestartPolicy{Name: RestartPolicyAlways}, hooks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.runs != 2 || len(restarts) != 2 || restarts[1] {
+		t.Fatalf("expected the container to exit after its restart, ran %d times", d.runs)
+	}
+	if len(d.kills) != 1 || d.kills[0] != int(syscall.SIGKILL) {
+		t.Fatalf("expected the restarted container to be killed got %v", d.kills)
+	}
+}
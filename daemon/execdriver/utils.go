@@ -71,7 +71,7 @@ func TweakCapabilities(basics, adds, drops []string) ([]string, error) {
 			continue
 		}
 		if !stringutils.InSlice(allCaps, cap) {
-			return nil, fmt.Errorf("Unknown capability drop: %q", cap)
+			return nil, fmt.Errorf("Unknown capability drop: %q, valid capabilities are ALL, %s", cap, strings.Join(allCaps, ", "))
 		}
 	}
 
@@ -101,7 +101,7 @@ func TweakCapabilities(basics, adds, drops []string) ([]string, error) {
 		}
 
 		if !stringutils.InSlice(allCaps, cap) {
-			return nil, fmt.Errorf("Unknown capability to add: %q", cap)
+			return nil, fmt.Errorf("Unknown capability to add: %q, valid capabilities are ALL, %s", cap, strings.Join(allCaps, ", "))
 		}
 
 		// add cap if not already in the list
@@ -34,6 +34,12 @@ type info struct {
 	driver *driver
 }
 
+func init() {
+	execdriver.Register("windows", func(config *execdriver.DriverConfig) (execdriver.Driver, error) {
+		return NewDriver(config.Root, config.InitPath)
+	})
+}
+
 func NewDriver(root, initPath string) (*driver, error) {
 	return &driver{
 		root:     root,
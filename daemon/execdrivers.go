@@ -0,0 +1,75 @@
+package daemon
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/daemon/execdriver/execdrivers"
+)
+
+// getExecDriver returns the exec driver registered with the name, creating
+// it the first time it is used.  The daemon's driver is returned for an
+// empty name.
+func (daemon *Daemon) getExecDriver(name string) (execdriver.Driver, error) {
+	if name == "" || name == daemon.config.ExecDriver {
+		return daemon.execDriver, nil
+	}
+	daemon.execDriversLock.Lock()
+	defer daemon.execDriversLock.Unlock()
+	if d, ok := daemon.execDrivers[name]; ok {
+		return d, nil
+	}
+	d, err := execdrivers.NewDriver(name, daemon.config.ExecOptions, daemon.config.ExecRoot, daemon.config.Root, daemon.sysInitPath, daemon.sysInfo)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Using exec driver %s for containers requesting %s", d.Name(), name)
+	daemon.execDrivers[name] = d
+	return d, nil
+}
+
+// usedExecDrivers returns the daemon's exec driver followed by the ones
+// requested by containers, by name.
+func (daemon *Daemon) usedExecDrivers() []execdriver.Driver {
+	drivers := []execdriver.Driver{daemon.execDriver}
+	daemon.execDriversLock.Lock()
+	defer daemon.execDriversLock.Unlock()
+	var names []string
+	for name := range daemon.execDrivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		drivers = append(drivers, daemon.execDrivers[name])
+	}
+	return drivers
+}
+
+// shutdownExecDrivers lets the exec drivers in use undo their changes to the
+// host.
+func (daemon *Daemon) shutdownExecDrivers() {
+	for _, d := range daemon.usedExecDrivers() {
+		if s, ok := d.(execdriver.Shutdowner); ok {
+			if err := s.Shutdown(); err != nil {
+				logrus.Errorf("Error during exec driver %s Shutdown(): %v", d.Name(), err)
+			}
+		}
+	}
+}
+
+// execDriver returns the exec driver running the container, the daemon's
+// unless the container requested another one.  It fails if the requested
+// driver cannot be used anymore.
+func (container *Container) execDriver() (execdriver.Driver, error) {
+	var name string
+	if container.hostConfig != nil {
+		name = container.hostConfig.ExecDriver
+	}
+	d, err := container.daemon.getExecDriver(name)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot use exec driver %s for %s: %v", name, container.ID, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,36 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/docker/docker/pkg/sysinfo"
+	"github.com/docker/docker/runconfig"
+)
+
+func TestExecDriverUnavailable(t *testing.T) {
+	d := &resetDriver{}
+	daemon, c := newStatsDaemon(d)
+	daemon.config = &Config{ExecDriver: "reset"}
+	daemon.sysInfo = &sysinfo.SysInfo{}
+	c.hostConfig = &runconfig.HostConfig{ExecDriver: "missing"}
+	c.SetRunning(1)
+
+	if _, err := c.execDriver(); err == nil {
+		t.Fatal("expected an error for a driver that cannot be used")
+	}
+	if err := c.Pause(); err == nil {
+		t.Fatal("expected pausing to fail without the container's driver")
+	}
+	if _, err := newContainerMonitor(c, runconfig.RestartPolicy{}); err == nil {
+		t.Fatal("expected starting to fail without the container's driver")
+	}
+	if err := daemon.ContainerStatsReset(c.ID); err == nil || len(d.reset) != 0 {
+		t.Fatalf("expected resetting the stats to fail without the container's driver, got %v", err)
+	}
+
+	// the daemon's driver is used by default
+	c.hostConfig.ExecDriver = ""
+	if ed, err := c.execDriver(); err != nil || ed != d {
+		t.Fatalf("expected the daemon's driver, got %v: %v", ed, err)
+	}
+}
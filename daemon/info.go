@@ -8,6 +8,7 @@ import (
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/autogen/dockerversion"
+	"github.com/docker/docker/daemon/execdriver"
 	"github.com/docker/docker/pkg/fileutils"
 	"github.com/docker/docker/pkg/parsers/kernel"
 	"github.com/docker/docker/pkg/parsers/operatingsystem"
@@ -88,6 +89,10 @@ func (daemon *Daemon) SystemInfo() (*types.Info, error) {
 		ExperimentalBuild:  utils.ExperimentalBuild(),
 	}
 
+	if s, ok := daemon.ExecutionDriver().(execdriver.StatusReporter); ok {
+		v.ExecDriverStatus = s.Status()
+	}
+
 	if httpProxy := os.Getenv("http_proxy"); httpProxy != "" {
 		v.HttpProxy = httpProxy
 	}
@@ -36,16 +36,32 @@ func (daemon *Daemon) ContainerInspect(name string) (*types.ContainerJSON, error
 	}
 
 	containerState := &types.ContainerState{
-		Running:    container.State.Running,
-		Paused:     container.State.Paused,
-		Restarting: container.State.Restarting,
-		OOMKilled:  container.State.OOMKilled,
-		Dead:       container.State.Dead,
-		Pid:        container.State.Pid,
-		ExitCode:   container.State.ExitCode,
-		Error:      container.State.Error,
-		StartedAt:  container.State.StartedAt,
-		FinishedAt: container.State.FinishedAt,
+		Running:       container.State.Running,
+		Paused:        container.State.Paused,
+		Restarting:    container.State.Restarting,
+		OOMKilled:     container.State.OOMKilled,
+		Dead:          container.State.Dead,
+		Pid:           container.State.Pid,
+		ExitCode:      container.State.ExitCode,
+		ExitSignal:    container.State.ExitSignal,
+		ExitInitiator: container.State.ExitInitiator,
+		Error:         container.State.Error,
+		StartedAt:     container.State.StartedAt,
+		FinishedAt:    container.State.FinishedAt,
+	}
+	if r := container.State.OOMReport; r != nil {
+		containerState.OOMReport = &types.OOMReport{
+			VictimPid:  r.VictimPid,
+			VictimComm: r.VictimComm,
+			Message:    r.Message,
+		}
+		for _, p := range r.Processes {
+			containerState.OOMReport.Processes = append(containerState.OOMReport.Processes, types.OOMProcess{
+				Pid:  p.Pid,
+				Comm: p.Comm,
+				RSS:  p.RSS,
+			})
+		}
 	}
 
 	contJSON := &types.ContainerJSON{
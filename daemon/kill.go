@@ -3,6 +3,8 @@ package daemon
 import (
 	"fmt"
 	"syscall"
+
+	"github.com/docker/docker/daemon/execdriver"
 )
 
 // ContainerKill send signal to the container
@@ -17,15 +19,58 @@ func (daemon *Daemon) ContainerKill(name string, sig uint64) error {
 
 	// If no signal is passed, or SIGKILL, perform regular Kill (SIGKILL + wait())
 	if sig == 0 || syscall.Signal(sig) == syscall.SIGKILL {
+		container.Lock()
+		container.HasBeenManuallyStopped = true
+		container.Unlock()
 		if err := container.Kill(); err != nil {
+			if execdriver.IsContainerStateError(err) {
+				return err
+			}
 			return fmt.Errorf("Cannot kill container %s: %s", name, err)
 		}
 	} else {
 		// Otherwise, just send the requested signal
 		if err := container.KillSig(int(sig)); err != nil {
+			if execdriver.IsContainerStateError(err) {
+				return err
+			}
 			return fmt.Errorf("Cannot kill container %s: %s", name, err)
 		}
 	}
 	container.LogEvent("kill")
 	return nil
 }
+
+// ContainerExecKill sends the signal, SIGKILL if none is given, to the process
+// of a running exec command.  The container keeps running.
+func (daemon *Daemon) ContainerExecKill(name string, sig uint64) error {
+	if sig == 0 {
+		sig = uint64(syscall.SIGKILL)
+	}
+	execConfig, err := daemon.getExecConfig(name)
+	if err != nil {
+		return err
+	}
+	ed, err := execConfig.Container.execDriver()
+	if err != nil {
+		return err
+	}
+	if err := execdriver.CheckFeature(ed, execdriver.FeatureSignal); err != nil {
+		return err
+	}
+	s, ok := ed.(execdriver.ProcessSignaler)
+	if !ok {
+		return fmt.Errorf("%s does not signal exec commands", ed.Name())
+	}
+	pid := execConfig.Pid
+	if pid == 0 {
+		return fmt.Errorf("Exec command %s is not running", name)
+	}
+	if err := s.SignalProcess(execConfig.Container.ID, pid, syscall.Signal(sig)); err != nil {
+		if execdriver.IsContainerStateError(err) {
+			return err
+		}
+		return fmt.Errorf("Cannot kill exec command %s: %s", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/daemon/execdriver"
+)
+
+// ExecDriverMetrics writes the metrics of the exec drivers in use, the
+// daemon's and the ones requested by containers, to out in the Prometheus
+// text format.  It fails if none of them reports metrics.
+func (daemon *Daemon) ExecDriverMetrics(out io.Writer) error {
+	var metrics []execdriver.Metric
+	reported := false
+	for _, d := range daemon.usedExecDrivers() {
+		if execdriver.CheckFeature(d, execdriver.FeatureMetrics) != nil {
+			continue
+		}
+		if m, ok := d.(execdriver.MetricsReporter); ok {
+			metrics = append(metrics, m.Metrics()...)
+			reported = true
+		}
+	}
+	if !reported {
+		if err := execdriver.CheckFeature(daemon.execDriver, execdriver.FeatureMetrics); err != nil {
+			return err
+		}
+		return fmt.Errorf("%s does not report metrics", daemon.execDriver.Name())
+	}
+	return execdriver.WriteMetrics(out, metrics)
+}
@@ -0,0 +1,55 @@
+package daemon
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/daemon/execdriver"
+)
+
+type metricsDriver struct {
+	execdriver.Driver
+	name string
+}
+
+func (d *metricsDriver) Name() string {
+	return d.name
+}
+
+func (d *metricsDriver) DriverCapabilities() execdriver.DriverCapabilities {
+	return execdriver.DriverCapabilities{Version: 1, Features: []execdriver.Feature{execdriver.FeatureMetrics}}
+}
+
+func (d *metricsDriver) Metrics() []execdriver.Metric {
+	return []execdriver.Metric{{
+		Name:    "docker_" + d.name + "_containers_active",
+		Help:    "Number of running containers.",
+		Type:    execdriver.MetricGauge,
+		Samples: []execdriver.MetricSample{{Value: 1}},
+	}}
+}
+
+func TestExecDriverMetricsAllDrivers(t *testing.T) {
+	daemon := &Daemon{
+		execDriver: &metricsDriver{name: "native"},
+		execDrivers: map[string]execdriver.Driver{
+			"reset": &resetDriver{},
+			"other": &metricsDriver{name: "other"},
+		},
+	}
+	var out bytes.Buffer
+	if err := daemon.ExecDriverMetrics(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"docker_native_containers_active", "docker_other_containers_active"} {
+		if !strings.Contains(out.String(), name+" 1\n") {
+			t.Fatalf("expected the metric %s, got %q", name, out.String())
+		}
+	}
+
+	daemon = &Daemon{execDriver: &resetDriver{}, execDrivers: make(map[string]execdriver.Driver)}
+	if err := daemon.ExecDriverMetrics(&out); err == nil {
+		t.Fatal("expected an error when no driver reports metrics")
+	}
+}
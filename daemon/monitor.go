@@ -3,80 +3,54 @@ package daemon
 import (
 	"io"
 	"os/exec"
-	"sync"
 	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/daemon/execdriver"
-	"github.com/docker/docker/pkg/stringid"
 	"github.com/docker/docker/runconfig"
 )
 
-const defaultTimeIncrement = 100
-
 // containerMonitor monitors the execution of a container's main process.
-// If a restart policy is specified for the container the monitor will ensure that the
-// process is restarted based on the rules of the policy.  When the container is finally stopped
-// the monitor will reset and cleanup any of the container resources such as networking allocations
-// and the rootfs
+// If a restart policy is specified for the container the monitor lets the
+// exec driver supervisor restart the process based on the rules of the
+// policy.  When the container is finally stopped the monitor will reset and
+// cleanup any of the container resources such as networking allocations and
+// the rootfs
 type containerMonitor struct {
-	mux sync.Mutex
-
 	// container is the container being monitored
 	container *Container
 
 	// restartPolicy is the current policy being applied to the container monitor
 	restartPolicy runconfig.RestartPolicy
 
-	// failureCount is the number of times the container has failed to
-	// start in a row
-	failureCount int
+	// driver is the exec driver running the container
+	driver execdriver.Driver
 
-	// shouldStop signals the monitor that the next time the container exits it is
-	// either because docker or the user asked for the container to be stopped
-	shouldStop bool
+	// supervisor runs the container's process and restarts it
+	supervisor *execdriver.Supervisor
 
 	// startSignal is a channel that is closes after the container initially starts
 	startSignal chan struct{}
 
-	// stopChan is used to signal to the monitor whenever there is a wait for the
-	// next restart so that the timeIncrement is not honored and the user is not
-	// left waiting for nothing to happen during this time
-	stopChan chan struct{}
-
-	// timeIncrement is the amount of time to wait between restarts
-	// this is in milliseconds
-	timeIncrement int
-
-	// lastStartTime is the time which the monitor last exec'd the container's process
-	lastStartTime time.Time
+	// oomEvents is set when the driver reports OOM kills of the current run
+	// as they happen, so they are not reported again on exit
+	oomEvents bool
 }
 
 // newContainerMonitor returns an initialized containerMonitor for the provided container
 // honoring the provided restart policy
-func newContainerMonitor(container *Container, policy runconfig.RestartPolicy) *containerMonitor {
+func newContainerMonitor(container *Container, policy runconfig.RestartPolicy) (*containerMonitor, error) {
+	ed, err := container.execDriver()
+	if err != nil {
+		return nil, err
+	}
 	return &containerMonitor{
 		container:     container,
 		restartPolicy: policy,
-		timeIncrement: defaultTimeIncrement,
-		stopChan:      make(chan struct{}),
+		driver:        ed,
+		supervisor:    execdriver.NewSupervisor(ed),
 		startSignal:   make(chan struct{}),
-	}
-}
-
-// Stop signals to the container monitor that it should stop monitoring the container
-// for exits the next time the process dies
-func (m *containerMonitor) ExitOnNext() {
-	m.mux.Lock()
-
-	// we need to protect having a double close of the channel when stop is called
-	// twice or else we will get a panic
-	if !m.shouldStop {
-		m.shouldStop = true
-		close(m.stopChan)
-	}
-
-	m.mux.Unlock()
+	}, nil
 }
 
 // Close closes the container's resources such as networking allocations and
@@ -100,144 +74,106 @@ func (m *containerMonitor) Close() error {
 // Start starts the containers process and monitors it according to the restart policy
 func (m *containerMonitor) Start() error {
 	var (
-		err        error
-		exitStatus execdriver.ExitStatus
 		// this variable indicates where we in execution flow:
 		// before Run or after
 		afterRun bool
+		// loggingFailed is set when the logging of a run could not be started,
+		// the process was not run
+		loggingFailed bool
 	)
 
-	// ensure that when the monitor finally exits we release the networking and unmount the rootfs
-	defer func() {
-		if afterRun {
-			m.container.Lock()
-			m.container.setStopped(&exitStatus)
-			defer m.container.Unlock()
-		}
-		m.Close()
-	}()
-
-	// reset the restart count
-	m.container.RestartCount = -1
-
-	for {
-		m.container.RestartCount++
-
-		if err := m.container.startLogging(); err != nil {
-			m.resetContainer(false)
-
-			return err
-		}
-
-		pipes := execdriver.NewPipes(m.container.stdin, m.container.stdout, m.container.stderr, m.container.Config.OpenStdin)
-
-		m.container.LogEvent("start")
-
-		m.lastStartTime = time.Now()
-
-		if exitStatus, err = m.container.daemon.Run(m.container, pipes, m.callback); err != nil {
-			// if we receive an internal error from the initial start of a container then lets
-			// return it instead of entering the restart loop
-			if m.container.RestartCount == 0 {
-				m.container.ExitCode = -1
-				m.resetContainer(false)
-
-				return err
+	hooks := execdriver.SupervisorHooks{
+		Prepare: func(restartCount int) (*execdriver.Pipes, error) {
+			m.container.RestartCount = restartCount
+			if err := m.container.startLogging(); err != nil {
+				loggingFailed = true
+				return nil, err
 			}
-
-			logrus.Errorf("Error running container: %s", err)
-		}
-
-		// here container.Lock is already lost
-		afterRun = true
-
-		m.resetMonitor(err == nil && exitStatus.ExitCode == 0)
-
-		if m.shouldRestart(exitStatus.ExitCode) {
-			m.container.SetRestarting(&exitStatus)
-			if exitStatus.OOMKilled {
+			m.container.LogEvent("start")
+			return execdriver.NewPipes(m.container.stdin, m.container.stdout, m.container.stderr, m.container.Config.OpenStdin), nil
+		},
+		Started: m.callback,
+		Exited: func(exitStatus execdriver.ExitStatus, err error, restart bool) {
+			// here container.Lock is already lost
+			afterRun = true
+			if err != nil {
+				logrus.Errorf("Error running container: %s", err)
+			}
+			if restart {
+				m.container.SetRestarting(&exitStatus)
+			}
+			if exitStatus.OOMKilled && !m.oomEvents {
 				m.container.LogEvent("oom")
 			}
 			m.container.LogEvent("die")
 			m.resetContainer(true)
+		},
+	}
 
-			// sleep with a small time increment between each restart to help avoid issues cased by quickly
-			// restarting the container because of some types of errors ( networking cut out, etc... )
-			m.waitForNextRestart()
+	exitStatus, err := m.supervisor.RunSupervised(m.container.command, m.supervisorPolicy(), hooks)
 
-			// we need to check this before reentering the loop because the waitForNextRestart could have
-			// been terminated by a request from a user
-			if m.shouldStop {
-				return err
-			}
-			continue
-		}
-		if exitStatus.OOMKilled {
-			m.container.LogEvent("oom")
+	// ensure that when the monitor finally exits we release the networking and unmount the rootfs
+	if afterRun {
+		m.container.Lock()
+		m.container.setStopped(&exitStatus)
+		defer m.container.Unlock()
+	} else if err != nil {
+		// if we receive an internal error from the initial start of a container then lets
+		// return it instead of entering the restart loop
+		if !loggingFailed {
+			m.container.ExitCode = -1
 		}
-		m.container.LogEvent("die")
-		m.resetContainer(true)
-		return err
+		m.resetContainer(false)
 	}
+	m.Close()
+	return err
 }
 
-// resetMonitor resets the stateful fields on the containerMonitor based on the
-// previous runs success or failure.  Regardless of success, if the container had
-// an execution time of more than 10s then reset the timer back to the default
-func (m *containerMonitor) resetMonitor(successful bool) {
-	executionTime := time.Now().Sub(m.lastStartTime).Seconds()
-
-	if executionTime > 10 {
-		m.timeIncrement = defaultTimeIncrement
-	} else {
-		// otherwise we need to increment the amount of time we wait before restarting
-		// the process.  We will build up by multiplying the increment by 2
-		m.timeIncrement *= 2
+// Reattach monitors a container left running by a previous instance of the
+// daemon until it exits and then applies the restart policy.  Its output was
+// connected to the previous instance and is not logged.
+func (m *containerMonitor) Reattach() {
+	container := m.container
+	if n, ok := m.driver.(execdriver.OOMNotifier); ok {
+		if events, err := n.SubscribeOOM(container.ID); err == nil {
+			m.oomEvents = true
+			go m.logOOMEvents(events)
+		}
 	}
 
-	// the container exited successfully so we need to reset the failure counter
-	if successful {
-		m.failureCount = 0
-	} else {
-		m.failureCount++
+	exitStatus, restart, err := m.supervisor.Reattach(container.command, m.supervisorPolicy(), container.StartedAt)
+	if err != nil {
+		logrus.Errorf("Error reattaching to container %s: %s", container.ID, err)
 	}
-}
 
-// waitForNextRestart waits with the default time increment to restart the container unless
-// a user or docker asks for the container to be stopped
-func (m *containerMonitor) waitForNextRestart() {
-	select {
-	case <-time.After(time.Duration(m.timeIncrement) * time.Millisecond):
-	case <-m.stopChan:
+	container.Lock()
+	container.setStopped(&exitStatus)
+	if err := container.Unmount(); err != nil {
+		logrus.Errorf("%v: Failed to umount filesystem: %v", container.ID, err)
 	}
-}
-
-// shouldRestart checks the restart policy and applies the rules to determine if
-// the container's process should be restarted
-func (m *containerMonitor) shouldRestart(exitCode int) bool {
-	m.mux.Lock()
-	defer m.mux.Unlock()
-
-	// do not restart if the user or docker has requested that this container be stopped
-	if m.shouldStop {
-		return false
+	if err := container.toDisk(); err != nil {
+		logrus.Errorf("Error dumping container %s state to disk: %s", container.ID, err)
+	}
+	container.Unlock()
+	if exitStatus.OOMKilled && !m.oomEvents {
+		container.LogEvent("oom")
 	}
+	container.LogEvent("die")
 
-	switch {
-	case m.restartPolicy.IsAlways():
-		return true
-	case m.restartPolicy.IsOnFailure():
-		// the default value of 0 for MaximumRetryCount means that we will not enforce a maximum count
-		if max := m.restartPolicy.MaximumRetryCount; max != 0 && m.failureCount > max {
-			logrus.Debugf("stopping restart of container %s because maximum failure could of %d has been reached",
-				stringid.TruncateID(m.container.ID), max)
-			return false
+	if restart {
+		if err := container.Start(); err != nil {
+			logrus.Errorf("Failed to restart container %s: %s", container.ID, err)
 		}
-
-		return exitCode != 0
 	}
+}
 
-	return false
+// supervisorPolicy returns the restart policy of the container for the
+// supervisor.
+func (m *containerMonitor) supervisorPolicy() execdriver.RestartPolicy {
+	return execdriver.RestartPolicy{
+		Name:              m.restartPolicy.Name,
+		MaximumRetryCount: m.restartPolicy.MaximumRetryCount,
+	}
 }
 
 // callback ensures that the container's state is properly updated after we
@@ -254,6 +190,15 @@ func (m *containerMonitor) callback(processConfig *execdriver.ProcessConfig, pid
 
 	m.container.setRunning(pid)
 
+	// report OOM kills as they happen if the driver can
+	m.oomEvents = false
+	if n, ok := m.driver.(execdriver.OOMNotifier); ok {
+		if events, err := n.SubscribeOOM(m.container.ID); err == nil {
+			m.oomEvents = true
+			go m.logOOMEvents(events)
+		}
+	}
+
 	// signal that the process has started
 	// close channel only if not closed
 	select {
@@ -267,6 +212,13 @@ func (m *containerMonitor) callback(processConfig *execdriver.ProcessConfig, pid
 	}
 }
 
+func (m *containerMonitor) logOOMEvents(events <-chan execdriver.OOMEvent) {
+	for e := range events {
+		logrus.Infof("OOM killer killed process %d (%s) in container %s, memory usage %d bytes", e.VictimPid, e.VictimComm, m.container.ID, e.MemoryUsage)
+		m.container.LogEvent("oom")
+	}
+}
+
 // resetContainer resets the container's IO and ensures that the command is able to be executed again
 // by copying the data into a new struct
 // if lock is true, then container locked during reset
@@ -1,6 +1,10 @@
 package daemon
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/docker/docker/daemon/execdriver"
+)
 
 // ContainerPause pauses a container
 func (daemon *Daemon) ContainerPause(name string) error {
@@ -10,6 +14,9 @@ func (daemon *Daemon) ContainerPause(name string) error {
 	}
 
 	if err := container.Pause(); err != nil {
+		if execdriver.IsContainerStateError(err) {
+			return err
+		}
 		return fmt.Errorf("Cannot pause container %s: %s", name, err)
 	}
 	container.LogEvent("pause")
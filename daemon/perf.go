@@ -0,0 +1,66 @@
+package daemon
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/docker/docker/daemon/execdriver"
+)
+
+// maxPerfDuration bounds how long a container is profiled for.
+const maxPerfDuration = 5 * time.Minute
+
+// ContainerPerf profiles a running container with perf for duration and
+// writes the result to out.  With the stat subcommand the counters are
+// written as text, with record the samples are written in perf's pipe
+// format, to be read with `perf report -i -`.
+func (daemon *Daemon) ContainerPerf(name, subcommand string, duration time.Duration, out io.Writer) error {
+	container, err := daemon.Get(name)
+	if err != nil {
+		return err
+	}
+	if !container.IsRunning() {
+		return fmt.Errorf("Container %s is not running", name)
+	}
+	ed, err := container.execDriver()
+	if err != nil {
+		return err
+	}
+	p, ok := ed.(execdriver.Profiler)
+	if !ok {
+		return fmt.Errorf("Unsupported: profiling is not supported by the %s driver", ed.Name())
+	}
+
+	var args []string
+	switch subcommand {
+	case "stat":
+	case "record":
+		args = []string{"-o", "-"}
+	default:
+		return fmt.Errorf("Invalid perf subcommand: %s", subcommand)
+	}
+	if duration < time.Second || duration > maxPerfDuration {
+		return fmt.Errorf("Invalid perf duration %s: it must be between 1s and %s", duration, maxPerfDuration)
+	}
+	// perf profiles the container's cgroup while the command runs
+	args = append(args, "--", "sleep", strconv.Itoa(int(duration/time.Second)))
+
+	cmd, err := p.PerfCommand(container.ID, subcommand, args...)
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	cmd.Stdout = out
+	cmd.Stderr = &stderr
+	// perf stat prints the counters on stderr
+	if subcommand == "stat" {
+		cmd.Stderr = out
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("perf %s failed: %v: %s", subcommand, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package daemon
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/execdriver"
+)
+
+// perfDriver echoes the perf command instead of running it.
+type perfDriver struct {
+	execdriver.Driver
+}
+
+func (d *perfDriver) Name() string {
+	return "perf"
+}
+
+func (d *perfDriver) PerfCommand(id, subcommand string, args ...string) (*exec.Cmd, error) {
+	return exec.Command("echo", append([]string{subcommand}, args...)...), nil
+}
+
+func TestContainerPerf(t *testing.T) {
+	daemon, c := newStatsDaemon(&perfDriver{})
+	var out bytes.Buffer
+	if err := daemon.ContainerPerf(c.ID, "stat", time.Second, &out); err == nil {
+		t.Fatal("expected an error for a container that is not running")
+	}
+
+	c.SetRunning(1)
+	tests := map[string]string{
+		"stat":   "stat -- sleep 2\n",
+		"record": "record -o - -- sleep 2\n",
+	}
+	for subcommand, expected := range tests {
+		out.Reset()
+		if err := daemon.ContainerPerf(c.ID, subcommand, 2*time.Second, &out); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != expected {
+			t.Fatalf("expected %q got %q", expected, out.String())
+		}
+	}
+
+	if err := daemon.ContainerPerf(c.ID, "top", time.Second, &out); err == nil {
+		t.Fatal("expected an error for an interactive subcommand")
+	}
+	if err := daemon.ContainerPerf(c.ID, "stat", time.Hour, &out); err == nil {
+		t.Fatal("expected an error for a duration above the maximum")
+	}
+}
@@ -0,0 +1,22 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+)
+
+// ReloadExecDriver applies new exec driver options without restarting the
+// daemon.
+func (daemon *Daemon) ReloadExecDriver(options []string) error {
+	r, ok := daemon.execDriver.(execdriver.Reloader)
+	if !ok {
+		return fmt.Errorf("Unsupported: reloading options is not supported by the %s driver", daemon.execDriver.Name())
+	}
+	if err := r.Reload(options); err != nil {
+		return err
+	}
+	logrus.Infof("Reloaded exec driver options %v", options)
+	return nil
+}
@@ -3,26 +3,32 @@ package daemon
 import (
 	"fmt"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/pkg/signal"
 	"github.com/docker/docker/pkg/units"
 )
 
 type State struct {
 	sync.Mutex
-	Running           bool
-	Paused            bool
-	Restarting        bool
-	OOMKilled         bool
-	removalInProgress bool // Not need for this to be persistent on disk.
-	Dead              bool
-	Pid               int
-	ExitCode          int
-	Error             string // contains last known error when starting the container
-	StartedAt         time.Time
-	FinishedAt        time.Time
-	waitChan          chan struct{}
+	Running                bool
+	Paused                 bool
+	Restarting             bool
+	OOMKilled              bool
+	OOMReport              *execdriver.OOMReport
+	removalInProgress      bool // Not need for this to be persistent on disk.
+	Dead                   bool
+	Pid                    int
+	ExitCode               int
+	ExitSignal             string // name of the signal that terminated the container
+	ExitInitiator          string // what caused the container to exit
+	HasBeenManuallyStopped bool   // stopped by the user, not started again by the daemon with unless-stopped
+	Error                  string // contains last known error when starting the container
+	StartedAt              time.Time
+	FinishedAt             time.Time
+	waitChan               chan struct{}
 }
 
 func NewState() *State {
@@ -160,6 +166,8 @@ func (s *State) setRunning(pid int) {
 	s.Paused = false
 	s.Restarting = false
 	s.ExitCode = 0
+	s.ExitSignal = ""
+	s.ExitInitiator = ""
 	s.Pid = pid
 	s.StartedAt = time.Now().UTC()
 	close(s.waitChan) // fire waiters for start
@@ -174,11 +182,15 @@ func (s *State) SetStopped(exitStatus *execdriver.ExitStatus) {
 
 func (s *State) setStopped(exitStatus *execdriver.ExitStatus) {
 	s.Running = false
+	s.Paused = false
 	s.Restarting = false
 	s.Pid = 0
 	s.FinishedAt = time.Now().UTC()
 	s.ExitCode = exitStatus.ExitCode
 	s.OOMKilled = exitStatus.OOMKilled
+	s.OOMReport = exitStatus.OOMReport
+	s.ExitSignal = signal.Name(syscall.Signal(exitStatus.Signal))
+	s.ExitInitiator = exitStatus.Initiator
 	close(s.waitChan) // fire waiters for stop
 	s.waitChan = make(chan struct{})
 }
@@ -195,6 +207,9 @@ func (s *State) SetRestarting(exitStatus *execdriver.ExitStatus) {
 	s.FinishedAt = time.Now().UTC()
 	s.ExitCode = exitStatus.ExitCode
 	s.OOMKilled = exitStatus.OOMKilled
+	s.OOMReport = exitStatus.OOMReport
+	s.ExitSignal = signal.Name(syscall.Signal(exitStatus.Signal))
+	s.ExitInitiator = exitStatus.Initiator
 	close(s.waitChan) // fire waiters for stop
 	s.waitChan = make(chan struct{})
 	s.Unlock()
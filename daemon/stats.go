@@ -2,6 +2,7 @@ package daemon
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 
 	"github.com/docker/docker/api/types"
@@ -10,7 +11,14 @@ import (
 	"github.com/docker/libcontainer/cgroups"
 )
 
-func (daemon *Daemon) ContainerStats(name string, stream bool, out io.Writer) error {
+// ContainerStats writes the stats of the container to out, once or until the
+// container stops if stream is set.  With size the size of the container's
+// filesystem is included, which is expensive to compute.
+func (daemon *Daemon) ContainerStats(name string, stream, size bool, out io.Writer) error {
+	container, err := daemon.Get(name)
+	if err != nil {
+		return err
+	}
 	updates, err := daemon.SubscribeToContainerStats(name)
 	if err != nil {
 		return err
@@ -20,8 +28,22 @@ func (daemon *Daemon) ContainerStats(name string, stream bool, out io.Writer) er
 		update := v.(*execdriver.ResourceStats)
 		ss := convertToAPITypes(update.Stats)
 		ss.MemoryStats.Limit = uint64(update.MemoryLimit)
+		ss.MemoryStats.OomKillDisable = update.OomKillDisable
+		ss.MemoryStats.OomScoreAdj = update.OomScoreAdj
 		ss.Read = update.Read
 		ss.CpuStats.SystemUsage = update.SystemUsage
+		ss.BlkioStats.IoThrottleServiceBytes = copyBlkioEntry(update.BlkioThrottleServiceBytes)
+		ss.BlkioStats.IoThrottleServiced = copyBlkioEntry(update.BlkioThrottleServiced)
+		if e := update.ExecStats; e != nil {
+			ss.ExecStats = &types.ExecStats{
+				Sessions:    e.Sessions,
+				CpuUsage:    e.CpuUsage,
+				MemoryUsage: e.MemoryUsage,
+			}
+		}
+		if size {
+			ss.SizeRw, ss.SizeRootFs = container.GetSize()
+		}
 		if err := enc.Encode(ss); err != nil {
 			// TODO: handle the specific broken pipe
 			daemon.UnsubscribeToContainerStats(name, updates)
@@ -34,6 +56,30 @@ func (daemon *Daemon) ContainerStats(name string, stream bool, out io.Writer) er
 	return nil
 }
 
+// ContainerStatsReset resets the peak memory usage and the failure counters
+// of a running container, so that they are measured from now on.
+func (daemon *Daemon) ContainerStatsReset(name string) error {
+	container, err := daemon.Get(name)
+	if err != nil {
+		return err
+	}
+	if !container.IsRunning() {
+		return fmt.Errorf("Container %s is not running", name)
+	}
+	ed, err := container.execDriver()
+	if err != nil {
+		return err
+	}
+	r, ok := ed.(execdriver.StatsResetter)
+	if !ok {
+		return fmt.Errorf("Unsupported: resetting the stats is not supported by the %s driver", ed.Name())
+	}
+	if err := r.ResetStats(container.ID); err != nil {
+		return err
+	}
+	return nil
+}
+
 // convertToAPITypes converts the libcontainer.Stats to the api specific
 // structs.  This is done to preserve API compatibility and versioning.
 func convertToAPITypes(ls *libcontainer.Stats) *types.Stats {
@@ -19,10 +19,12 @@ import (
 // network and cgroup stats for a registered container at the specified
 // interval.  The collector allows non-running containers to be added
 // and will start processing stats when they are started.
-func newStatsCollector(interval time.Duration) *statsCollector {
+func newStatsCollector(interval time.Duration, driver execdriver.Driver) *statsCollector {
 	s := &statsCollector{
 		interval:   interval,
+		driver:     driver,
 		publishers: make(map[*Container]*pubsub.Publisher),
+		streams:    make(map[*Container]*driverStream),
 		clockTicks: uint64(system.GetClockTicks()),
 		bufReader:  bufio.NewReaderSize(nil, 128),
 	}
@@ -34,9 +36,19 @@ func newStatsCollector(interval time.Duration) *statsCollector {
 type statsCollector struct {
 	m          sync.Mutex
 	interval   time.Duration
+	driver     execdriver.Driver
 	clockTicks uint64
 	publishers map[*Container]*pubsub.Publisher
-	bufReader  *bufio.Reader
+	// streams are the containers whose driver streams their stats, they
+	// are not collected by run
+	streams   map[*Container]*driverStream
+	bufMu     sync.Mutex // guards bufReader
+	bufReader *bufio.Reader
+}
+
+// driverStream is the stream of the stats of a container from its driver.
+type driverStream struct {
+	stop func()
 }
 
 // collect registers the container with the collector and adds it to
@@ -50,14 +62,72 @@ func (s *statsCollector) collect(c *Container) chan interface{} {
 		publisher = pubsub.NewPublisher(100*time.Millisecond, 1024)
 		s.publishers[c] = publisher
 	}
+	if _, streaming := s.streams[c]; !streaming && c.IsRunning() {
+		s.stream(c, publisher)
+	}
 	return publisher.Subscribe()
 }
 
+// stream has the stats of the container streamed by its driver, when it
+// implements execdriver.StatsStreamer, instead of collecting them.  It must
+// be called with the lock held.
+func (s *statsCollector) stream(c *Container, publisher *pubsub.Publisher) {
+	ed, err := c.execDriver()
+	if err != nil {
+		logrus.Errorf("streaming stats for %s: %v", c.ID, err)
+		return
+	}
+	ss, ok := ed.(execdriver.StatsStreamer)
+	if !ok {
+		return
+	}
+	stats, stop, err := ss.StatsStream(c.ID, s.interval)
+	if err != nil {
+		if err != execdriver.ErrNotRunning {
+			logrus.Errorf("streaming stats for %s: %v", c.ID, err)
+		}
+		return
+	}
+	stream := &driverStream{stop: stop}
+	s.streams[c] = stream
+	go s.forward(c, publisher, stream, stats)
+}
+
+// forward publishes the stats of the stream until the driver closes it,
+// when the container exits or the stream is stopped.  The stats of the
+// container are collected by run again afterwards.
+func (s *statsCollector) forward(c *Container, publisher *pubsub.Publisher, stream *driverStream, stats <-chan *execdriver.ResourceStats) {
+	for st := range stats {
+		systemUsage, err := s.getSystemCpuUsage()
+		if err != nil {
+			logrus.Errorf("collecting system cpu usage: %v", err)
+			continue
+		}
+		st.SystemUsage = systemUsage
+		publisher.Publish(st)
+	}
+	s.m.Lock()
+	if s.streams[c] == stream {
+		delete(s.streams, c)
+	}
+	s.m.Unlock()
+}
+
+// stopStream stops the stream of the container's stats, if any.  It must be
+// called with the lock held.
+func (s *statsCollector) stopStream(c *Container) {
+	if stream, ok := s.streams[c]; ok {
+		stream.stop()
+		delete(s.streams, c)
+	}
+}
+
 // stopCollection closes the channels for all subscribers and removes
 // the container from metrics collection.
 func (s *statsCollector) stopCollection(c *Container) {
 	s.m.Lock()
 	if publisher, exists := s.publishers[c]; exists {
+		s.stopStream(c)
 		publisher.Close()
 		delete(s.publishers, c)
 	}
@@ -71,58 +141,98 @@ func (s *statsCollector) unsubscribe(c *Container, ch chan interface{}) {
 	if publisher != nil {
 		publisher.Evict(ch)
 		if publisher.Len() == 0 {
+			s.stopStream(c)
 			delete(s.publishers, c)
 		}
 	}
 	s.m.Unlock()
 }
 
+// publishersPair is a watched container and the publisher of its stats.
+type publishersPair struct {
+	container *Container
+	publisher *pubsub.Publisher
+}
+
 func (s *statsCollector) run() {
-	type publishersPair struct {
-		container *Container
-		publisher *pubsub.Publisher
-	}
 	// we cannot determine the capacity here.
 	// it will grow enough in first iteration
 	var pairs []publishersPair
 
 	for range time.Tick(s.interval) {
-		systemUsage, err := s.getSystemCpuUsage()
-		if err != nil {
-			logrus.Errorf("collecting system cpu usage: %v", err)
+		pairs = s.collectStats(pairs)
+	}
+}
+
+// collectStats publishes the stats of the watched containers whose stats are
+// not streamed.  It reuses the pairs of the previous collection and returns
+// them.
+func (s *statsCollector) collectStats(pairs []publishersPair) []publishersPair {
+	systemUsage, err := s.getSystemCpuUsage()
+	if err != nil {
+		logrus.Errorf("collecting system cpu usage: %v", err)
+		return pairs
+	}
+
+	// it does not make sense in the first iteration,
+	// but saves allocations in further iterations
+	pairs = pairs[:0]
+
+	s.m.Lock()
+	for container, publisher := range s.publishers {
+		if _, streaming := s.streams[container]; !streaming && container.IsRunning() {
+			// started, or restarted, since it was subscribed to
+			s.stream(container, publisher)
+		}
+		if _, streaming := s.streams[container]; streaming {
 			continue
 		}
+		// copy pointers here to release the lock ASAP
+		pairs = append(pairs, publishersPair{container, publisher})
+	}
+	s.m.Unlock()
 
-		// it does not make sense in the first iteration,
-		// but saves allocations in further iterations
-		pairs = pairs[:0]
-
-		s.m.Lock()
-		for container, publisher := range s.publishers {
-			// copy pointers here to release the lock ASAP
-			pairs = append(pairs, publishersPair{container, publisher})
+	// when many containers are watched, as with `docker stats` on all
+	// containers, drivers that can collect the stats of all containers
+	// at once save a call per container
+	var all map[string]*execdriver.ResourceStats
+	if sa, ok := s.driver.(execdriver.StatsAller); ok && len(pairs) >= batchStatsMin {
+		if all, err = sa.StatsAll(); err != nil {
+			logrus.Errorf("collecting stats: %v", err)
+			return pairs
 		}
-		s.m.Unlock()
+	}
 
-		for _, pair := range pairs {
-			stats, err := pair.container.Stats()
-			if err != nil {
-				if err != execdriver.ErrNotRunning {
-					logrus.Errorf("collecting stats for %s: %v", pair.container.ID, err)
-				}
+	for _, pair := range pairs {
+		var stats *execdriver.ResourceStats
+		// containers can be run by another driver than the daemon's
+		if ed, _ := pair.container.execDriver(); all != nil && ed == s.driver {
+			if stats = all[pair.container.ID]; stats == nil {
 				continue
 			}
-			stats.SystemUsage = systemUsage
-			pair.publisher.Publish(stats)
+		} else if stats, err = pair.container.Stats(); err != nil {
+			if err != execdriver.ErrNotRunning {
+				logrus.Errorf("collecting stats for %s: %v", pair.container.ID, err)
+			}
+			continue
 		}
+		stats.SystemUsage = systemUsage
+		pair.publisher.Publish(stats)
 	}
+	return pairs
 }
 
+// batchStatsMin is the number of watched containers from which the stats of
+// all containers are collected at once.
+const batchStatsMin = 10
+
 const nanoSeconds = 1e9
 
 // getSystemCpuUSage returns the host system's cpu usage in nanoseconds
 // for the system to match the cgroup readings are returned in the same format.
 func (s *statsCollector) getSystemCpuUsage() (uint64, error) {
+	s.bufMu.Lock()
+	defer s.bufMu.Unlock()
 	var line string
 	f, err := os.Open("/proc/stat")
 	if err != nil {
@@ -0,0 +1,196 @@
+package daemon
+
+import (
+	"bufio"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/pkg/pubsub"
+)
+
+// streamDriver streams the stats sent on its channel.
+type streamDriver struct {
+	execdriver.Driver
+	mu      sync.Mutex
+	streams int
+	stats   chan *execdriver.ResourceStats
+	stopped bool
+}
+
+func (d *streamDriver) Name() string {
+	return "stream"
+}
+
+func (d *streamDriver) StatsStream(id string, interval time.Duration) (<-chan *execdriver.ResourceStats, func(), error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.streams++
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			d.mu.Lock()
+			d.stopped = true
+			d.mu.Unlock()
+			close(d.stats)
+		})
+	}
+	return d.stats, stop, nil
+}
+
+func newTestStatsCollector() *statsCollector {
+	return &statsCollector{
+		interval:   time.Second,
+		publishers: make(map[*Container]*pubsub.Publisher),
+		streams:    make(map[*Container]*driverStream),
+		clockTicks: 100,
+		bufReader:  bufio.NewReaderSize(nil, 128),
+	}
+}
+
+func newStreamedContainer(d execdriver.Driver) *Container {
+	c := &Container{
+		CommonContainer: CommonContainer{
+			ID:     "5a4ff6a163ad4533d22d69a2b8960bf7fafdcba06e72d2febdba229008b0bf57",
+			State:  NewState(),
+			daemon: &Daemon{execDriver: d},
+		},
+	}
+	c.SetRunning(1)
+	return c
+}
+
+func TestStatsCollectorStream(t *testing.T) {
+	d := &streamDriver{stats: make(chan *execdriver.ResourceStats)}
+	s := newTestStatsCollector()
+	c := newStreamedContainer(d)
+
+	ch1 := s.collect(c)
+	ch2 := s.collect(c)
+	if d.streams != 1 {
+		t.Fatalf("expected the subscribers to share a stream, got %d", d.streams)
+	}
+	d.stats <- &execdriver.ResourceStats{}
+	for _, ch := range []chan interface{}{ch1, ch2} {
+		select {
+		case v := <-ch:
+			if v.(*execdriver.ResourceStats).SystemUsage == 0 {
+				t.Fatal("expected the system usage set on the streamed stats")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for the streamed stats")
+		}
+	}
+
+	s.unsubscribe(c, ch1)
+	if d.stopped {
+		t.Fatal("expected the stream to go on while it has subscribers")
+	}
+	s.unsubscribe(c, ch2)
+	if !d.stopped {
+		t.Fatal("expected the stream stopped without subscribers")
+	}
+}
+
+func TestStatsCollectorStreamClosedOnExit(t *testing.T) {
+	d := &streamDriver{stats: make(chan *execdriver.ResourceStats)}
+	s := newTestStatsCollector()
+	c := newStreamedContainer(d)
+
+	ch := s.collect(c)
+	// the driver closes the stream when the container exits
+	close(d.stats)
+	for i := 0; ; i++ {
+		s.m.Lock()
+		_, streaming := s.streams[c]
+		s.m.Unlock()
+		if !streaming {
+			break
+		}
+		if i == 100 {
+			t.Fatal("expected the stream forgotten once closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// the subscribers wait for the container to start again
+	select {
+	case _, ok := <-ch:
+		t.Fatalf("expected the subscriber kept, received %v", ok)
+	default:
+	}
+	s.stopCollection(c)
+	if _, ok := <-ch; ok {
+		t.Fatal("expected the subscriber closed once the collection stopped")
+	}
+}
+
+// batchDriver collects the stats of the containers one by one or all at
+// once, leaving out the ones that exited.
+type batchDriver struct {
+	execdriver.Driver
+	exited         map[string]bool
+	statsCalls     int
+	statsAllCalls  int
+	containerCount int
+}
+
+func (d *batchDriver) Name() string {
+	return "batch"
+}
+
+func (d *batchDriver) Stats(id string) (*execdriver.ResourceStats, error) {
+	d.statsCalls++
+	if d.exited[id] {
+		return nil, execdriver.ErrNotRunning
+	}
+	return &execdriver.ResourceStats{}, nil
+}
+
+func (d *batchDriver) StatsAll() (map[string]*execdriver.ResourceStats, error) {
+	d.statsAllCalls++
+	all := make(map[string]*execdriver.ResourceStats)
+	for i := 0; i < d.containerCount; i++ {
+		if id := strconv.Itoa(i); !d.exited[id] {
+			all[id] = &execdriver.ResourceStats{}
+		}
+	}
+	return all, nil
+}
+
+func TestStatsCollectorBatch(t *testing.T) {
+	for _, count := range []int{batchStatsMin - 1, batchStatsMin} {
+		d := &batchDriver{exited: map[string]bool{"0": true}, containerCount: count}
+		s := newTestStatsCollector()
+		s.driver = d
+		daemon := &Daemon{execDriver: d}
+		subscribers := make(map[string]chan interface{})
+		for i := 0; i < count; i++ {
+			c := &Container{CommonContainer: CommonContainer{ID: strconv.Itoa(i), State: NewState(), daemon: daemon}}
+			subscribers[c.ID] = s.collect(c)
+		}
+
+		s.collectStats(nil)
+		if count < batchStatsMin && (d.statsCalls != count || d.statsAllCalls != 0) {
+			t.Fatalf("expected the stats of %d containers collected one by one, got %d calls and %d batches", count, d.statsCalls, d.statsAllCalls)
+		}
+		if count >= batchStatsMin && (d.statsCalls != 0 || d.statsAllCalls != 1) {
+			t.Fatalf("expected the stats of %d containers collected at once, got %d calls and %d batches", count, d.statsCalls, d.statsAllCalls)
+		}
+		// the stats are published by the time they are collected
+		for id, ch := range subscribers {
+			select {
+			case <-ch:
+				if d.exited[id] {
+					t.Fatalf("expected no stats for the exited container %s", id)
+				}
+			default:
+				if !d.exited[id] {
+					t.Fatalf("expected stats for the container %s", id)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/docker/docker/daemon/execdriver"
+)
+
+// resetDriver records the containers whose stats it reset.
+type resetDriver struct {
+	execdriver.Driver
+	reset []string
+}
+
+func (d *resetDriver) Name() string {
+	return "reset"
+}
+
+func (d *resetDriver) ResetStats(id string) error {
+	d.reset = append(d.reset, id)
+	return nil
+}
+
+func newStatsDaemon(d execdriver.Driver) (*Daemon, *Container) {
+	daemon := &Daemon{execDriver: d, containers: &contStore{s: make(map[string]*Container)}}
+	c := &Container{
+		CommonContainer: CommonContainer{
+			ID:     "5a4ff6a163ad4533d22d69a2b8960bf7fafdcba06e72d2febdba229008b0bf57",
+			State:  NewState(),
+			daemon: daemon,
+		},
+	}
+	daemon.containers.Add(c.ID, c)
+	return daemon, c
+}
+
+func TestContainerStatsReset(t *testing.T) {
+	d := &resetDriver{}
+	daemon, c := newStatsDaemon(d)
+	if err := daemon.ContainerStatsReset(c.ID); err == nil {
+		t.Fatal("expected an error for a container that is not running")
+	}
+	if len(d.reset) != 0 {
+		t.Fatalf("expected no reset for a container that is not running, got %v", d.reset)
+	}
+
+	c.SetRunning(1)
+	if err := daemon.ContainerStatsReset(c.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.reset) != 1 || d.reset[0] != c.ID {
+		t.Fatalf("expected the stats of %s reset, got %v", c.ID, d.reset)
+	}
+}
+
+func TestContainerStatsResetUnsupported(t *testing.T) {
+	daemon, c := newStatsDaemon(&streamDriver{})
+	c.SetRunning(1)
+	if err := daemon.ContainerStatsReset(c.ID); err == nil {
+		t.Fatal("expected an error for a driver that does not reset stats")
+	}
+}
@@ -10,6 +10,9 @@ func (daemon *Daemon) ContainerStop(name string, seconds int) error {
 	if !container.IsRunning() {
 		return fmt.Errorf("Container already stopped")
 	}
+	container.Lock()
+	container.HasBeenManuallyStopped = true
+	container.Unlock()
 	if err := container.Stop(seconds); err != nil {
 		return fmt.Errorf("Cannot stop container %s: %s\n", name, err)
 	}
@@ -5,15 +5,13 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/daemon/execdriver"
 )
 
 func (daemon *Daemon) ContainerTop(name string, psArgs string) (*types.ContainerProcessList, error) {
-	if psArgs == "" {
-		psArgs = "-ef"
-	}
-
 	container, err := daemon.Get(name)
 	if err != nil {
 		return nil, err
@@ -23,7 +21,23 @@ func (daemon *Daemon) ContainerTop(name string, psArgs string) (*types.Container
 		return nil, fmt.Errorf("Container %s is not running", name)
 	}
 
-	pids, err := daemon.ExecutionDriver().GetPidsForContainer(container.ID)
+	ed, err := container.execDriver()
+	if err != nil {
+		return nil, err
+	}
+	// without ps arguments the driver describes the processes if it can
+	if l, ok := ed.(execdriver.ProcessLister); ok && psArgs == "" {
+		procs, err := l.GetProcesses(container.ID)
+		if err != nil {
+			return nil, err
+		}
+		return processList(procs), nil
+	}
+	if psArgs == "" {
+		psArgs = "-ef"
+	}
+
+	pids, err := ed.GetPidsForContainer(container.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -70,3 +84,29 @@ func (daemon *Daemon) ContainerTop(name string, psArgs string) (*types.Container
 	}
 	return procList, nil
 }
+
+// processList formats the processes described by the driver like ps -f with
+// the resident memory in KB.
+func processList(procs []*execdriver.ProcessInfo) *types.ContainerProcessList {
+	procList := &types.ContainerProcessList{
+		Titles: []string{"UID", "PID", "PPID", "STIME", "TIME", "RSS", "CMD"},
+	}
+	now := time.Now()
+	for _, p := range procs {
+		stime := p.StartTime.Format("15:04")
+		if p.StartTime.Year() != now.Year() || p.StartTime.YearDay() != now.YearDay() {
+			stime = p.StartTime.Format("Jan02")
+		}
+		cpu := int64(p.CpuTime / time.Second)
+		procList.Processes = append(procList.Processes, []string{
+			strconv.Itoa(p.Uid),
+			strconv.Itoa(p.Pid),
+			strconv.Itoa(p.PPid),
+			stime,
+			fmt.Sprintf("%02d:%02d:%02d", cpu/3600, cpu/60%60, cpu%60),
+			strconv.FormatUint(p.Rss/1024, 10),
+			p.Cmdline,
+		})
+	}
+	return procList
+}
@@ -1,6 +1,10 @@
 package daemon
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/docker/docker/daemon/execdriver"
+)
 
 // ContainerUnpause unpauses a container
 func (daemon *Daemon) ContainerUnpause(name string) error {
@@ -10,6 +14,9 @@ func (daemon *Daemon) ContainerUnpause(name string) error {
 	}
 
 	if err := container.Unpause(); err != nil {
+		if execdriver.IsContainerStateError(err) {
+			return err
+		}
 		return fmt.Errorf("Cannot unpause container %s: %s", name, err)
 	}
 	container.LogEvent("unpause")
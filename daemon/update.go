@@ -0,0 +1,139 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/runconfig"
+)
+
+// ContainerUpdate changes the resource limits of a container.  The non-zero
+// resource fields of update replace the ones of the container and, if the
+// container is running, are applied to it right away.
+func (daemon *Daemon) ContainerUpdate(name string, update *runconfig.HostConfig) ([]string, error) {
+	container, err := daemon.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	container.Lock()
+	defer container.Unlock()
+
+	hostConfig := *container.hostConfig
+	mergeResources(&hostConfig, update)
+	warnings, err := daemon.verifyHostConfig(&hostConfig)
+	if err != nil {
+		return warnings, err
+	}
+
+	if container.Running && container.command != nil {
+		ed, err := container.execDriver()
+		if err != nil {
+			return warnings, err
+		}
+		u, ok := ed.(execdriver.Updater)
+		if !ok || execdriver.CheckFeature(ed, execdriver.FeatureUpdate) != nil {
+			return warnings, fmt.Errorf("Unsupported: updating a running container is not supported by the %s driver", ed.Name())
+		}
+		resources := *container.command.Resources
+		resources.Memory = hostConfig.Memory
+		resources.MemorySwap = hostConfig.MemorySwap
+		resources.CpuShares = hostConfig.CpuShares
+		resources.CpusetCpus = hostConfig.CpusetCpus
+		resources.CpusetMems = hostConfig.CpusetMems
+		resources.CpuPeriod = hostConfig.CpuPeriod
+		resources.CpuQuota = hostConfig.CpuQuota
+		resources.BlkioWeight = hostConfig.BlkioWeight
+		if err := u.Update(container.ID, &resources); err != nil {
+			return warnings, err
+		}
+		container.command.Resources = &resources
+	}
+
+	container.hostConfig = &hostConfig
+	if err := container.toDisk(); err != nil {
+		return warnings, err
+	}
+	container.LogEvent("update")
+	return warnings, nil
+}
+
+// ContainerAddDevice gives a running container access to the host devices of
+// mapping, without restarting it.  Only the devices cgroup of the container is
+// changed, the device nodes are not created in the container.  The devices
+// are kept in the host config so that they are created when the container
+// starts again.
+func (daemon *Daemon) ContainerAddDevice(name string, mapping runconfig.DeviceMapping) error {
+	container, err := daemon.Get(name)
+	if err != nil {
+		return err
+	}
+
+	container.Lock()
+	defer container.Unlock()
+
+	if !container.Running {
+		return fmt.Errorf("Container %s is not running", name)
+	}
+	ed, err := container.execDriver()
+	if err != nil {
+		return err
+	}
+	a, ok := ed.(execdriver.DeviceAdder)
+	if !ok || execdriver.CheckFeature(ed, execdriver.FeatureAddDevice) != nil {
+		return fmt.Errorf("Unsupported: adding a device to a running container is not supported by the %s driver", ed.Name())
+	}
+	if mapping.PathInContainer == "" {
+		mapping.PathInContainer = mapping.PathOnHost
+	}
+	if mapping.CgroupPermissions == "" {
+		mapping.CgroupPermissions = "rwm"
+	}
+	devs, err := getDevicesFromPath(mapping)
+	if err != nil {
+		return err
+	}
+	if len(devs) == 0 {
+		return fmt.Errorf("No devices found in %s", mapping.PathOnHost)
+	}
+	for _, dev := range devs {
+		if err := a.AddDevice(container.ID, dev); err != nil {
+			return err
+		}
+	}
+
+	container.hostConfig.Devices = append(container.hostConfig.Devices, mapping)
+	if err := container.toDisk(); err != nil {
+		return err
+	}
+	container.LogEvent("add-device")
+	return nil
+}
+
+// mergeResources copies the resource limits set in update to hostConfig.
+func mergeResources(hostConfig, update *runconfig.HostConfig) {
+	if update.Memory != 0 {
+		hostConfig.Memory = update.Memory
+	}
+	if update.MemorySwap != 0 {
+		hostConfig.MemorySwap = update.MemorySwap
+	}
+	if update.CpuShares != 0 {
+		hostConfig.CpuShares = update.CpuShares
+	}
+	if update.CpusetCpus != "" {
+		hostConfig.CpusetCpus = update.CpusetCpus
+	}
+	if update.CpusetMems != "" {
+		hostConfig.CpusetMems = update.CpusetMems
+	}
+	if update.CpuPeriod != 0 {
+		hostConfig.CpuPeriod = update.CpuPeriod
+	}
+	if update.CpuQuota != 0 {
+		hostConfig.CpuQuota = update.CpuQuota
+	}
+	if update.BlkioWeight != 0 {
+		hostConfig.BlkioWeight = update.BlkioWeight
+	}
+}
@@ -0,0 +1,75 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/docker/docker/daemon/events"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/runconfig"
+	"github.com/docker/libcontainer/configs"
+)
+
+// deviceDriver records the devices added to the containers.
+type deviceDriver struct {
+	execdriver.Driver
+	devices []*configs.Device
+}
+
+func (d *deviceDriver) Name() string {
+	return "device"
+}
+
+func (d *deviceDriver) DriverCapabilities() execdriver.DriverCapabilities {
+	return execdriver.DriverCapabilities{Version: execdriver.APIVersion, Features: []execdriver.Feature{execdriver.FeatureAddDevice}}
+}
+
+func (d *deviceDriver) AddDevice(id string, dev *configs.Device) error {
+	d.devices = append(d.devices, dev)
+	return nil
+}
+
+func TestContainerAddDevice(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-add-device-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	d := &deviceDriver{}
+	daemon, c := newStatsDaemon(d)
+	daemon.EventsService = events.New()
+	c.root = root
+	c.Config = &runconfig.Config{}
+	c.hostConfig = &runconfig.HostConfig{}
+
+	mapping := runconfig.DeviceMapping{PathOnHost: "/dev/null"}
+	if err := daemon.ContainerAddDevice(c.ID, mapping); err == nil {
+		t.Fatal("expected an error for a container that is not running")
+	}
+
+	c.SetRunning(1)
+	if err := daemon.ContainerAddDevice(c.ID, mapping); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.devices) != 1 || d.devices[0].Path != "/dev/null" || d.devices[0].Permissions != "rwm" {
+		t.Fatalf("expected /dev/null added with the default permissions, got %v", d.devices)
+	}
+	devices := c.hostConfig.Devices
+	if len(devices) != 1 || devices[0].PathInContainer != "/dev/null" {
+		t.Fatalf("expected the device kept in the host config, got %v", devices)
+	}
+
+	if err := daemon.ContainerAddDevice(c.ID, runconfig.DeviceMapping{PathOnHost: root}); err == nil {
+		t.Fatal("expected an error for a path without devices")
+	}
+}
+
+func TestContainerAddDeviceUnsupported(t *testing.T) {
+	daemon, c := newStatsDaemon(&resetDriver{})
+	c.SetRunning(1)
+	if err := daemon.ContainerAddDevice(c.ID, runconfig.DeviceMapping{PathOnHost: "/dev/null"}); err == nil {
+		t.Fatal("expected an error for a driver that does not add devices")
+	}
+}
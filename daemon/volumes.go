@@ -13,6 +13,7 @@ import (
 	"github.com/docker/docker/pkg/chrootarchive"
 	"github.com/docker/docker/pkg/mount"
 	"github.com/docker/docker/pkg/symlink"
+	"github.com/docker/docker/runconfig"
 )
 
 type volumeMount struct {
@@ -21,6 +22,7 @@ type volumeMount struct {
 	writable      bool
 	copyData      bool
 	from          string
+	propagation   string
 }
 
 func (container *Container) createVolumes() error {
@@ -184,7 +186,12 @@ func parseBindMountSpec(spec string) (*volumeMount, error) {
 	case 3:
 		mnt.hostPath = arr[0]
 		mnt.containerPath = arr[1]
-		mnt.writable = validMountMode(arr[2]) && arr[2] == "rw"
+		writable, propagation, err := runconfig.ParseBindMode(arr[2])
+		if err != nil {
+			return nil, err
+		}
+		mnt.writable = writable
+		mnt.propagation = propagation
 	default:
 		return nil, fmt.Errorf("Invalid volume specification: %s", spec)
 	}
@@ -198,6 +205,18 @@ func parseBindMountSpec(spec string) (*volumeMount, error) {
 	return mnt, nil
 }
 
+// bindPropagations returns the propagations given to the bind mounts of the
+// container, by container path.
+func (container *Container) bindPropagations() map[string]string {
+	propagations := make(map[string]string)
+	for _, spec := range container.hostConfig.Binds {
+		if mnt, err := parseBindMountSpec(spec); err == nil && mnt.propagation != "" {
+			propagations[mnt.containerPath] = mnt.propagation
+		}
+	}
+	return propagations
+}
+
 func parseVolumesFromSpec(spec string) (string, string, error) {
 	specParts := strings.SplitN(spec, ":", 2)
 	if len(specParts) == 0 {
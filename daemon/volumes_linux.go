@@ -44,11 +44,13 @@ func (container *Container) setupMounts() error {
 	// volumes. For instance if you use -v /usr:/usr and the host later mounts /usr/share you
 	// want this new mount in the container
 	// These mounts must be ordered based on the length of the path that it is being mounted to (lexicographic)
+	propagations := container.bindPropagations()
 	for _, path := range container.sortedVolumeMounts() {
 		mounts = append(mounts, execdriver.Mount{
 			Source:      container.Volumes[path],
 			Destination: path,
 			Writable:    container.VolumesRW[path],
+			Propagation: propagations[path],
 		})
 	}
 
@@ -3,6 +3,7 @@ package signal
 import (
 	"os"
 	"os/signal"
+	"syscall"
 )
 
 func CatchAll(sigc chan os.Signal) {
@@ -17,3 +18,19 @@ func StopCatch(sigc chan os.Signal) {
 	signal.Stop(sigc)
 	close(sigc)
 }
+
+// Name returns the name of the signal, e.g. SIGKILL, or an empty string if
+// the signal is unknown.  When a signal has aliases the first name in
+// alphabetical order is used.
+func Name(sig syscall.Signal) string {
+	var name string
+	for n, s := range SignalMap {
+		if s == sig && (name == "" || n < name) {
+			name = n
+		}
+	}
+	if name == "" {
+		return ""
+	}
+	return "SIG" + name
+}
@@ -2,6 +2,7 @@ package ulimit
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -61,6 +62,16 @@ var ulimitNameMapping = map[string]int{
 	"stack":      RLIMIT_STACK,
 }
 
+// Names returns the names of the supported ulimits in alphabetical order.
+func Names() []string {
+	names := make([]string, 0, len(ulimitNameMapping))
+	for name := range ulimitNameMapping {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func Parse(val string) (*Ulimit, error) {
 	parts := strings.SplitN(val, "=", 2)
 	if len(parts) != 2 {
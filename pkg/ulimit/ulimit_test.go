@@ -53,3 +53,18 @@ func TestStringOutput(t *testing.T) {
 		t.Fatal("expected String to return nofile=512:1024, but got", s)
 	}
 }
+
+func TestNames(t *testing.T) {
+	names := Names()
+	if len(names) != len(ulimitNameMapping) {
+		t.Fatalf("expected %d names, got %d", len(ulimitNameMapping), len(names))
+	}
+	for i, name := range names {
+		if _, err := (&Ulimit{Name: name}).GetRlimit(); err != nil {
+			t.Fatal(err)
+		}
+		if i > 0 && names[i-1] >= name {
+			t.Fatalf("names are not sorted: %v", names)
+		}
+	}
+}
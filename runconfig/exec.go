@@ -2,6 +2,7 @@ package runconfig
 
 import (
 	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/pkg/units"
 )
 
 type ExecConfig struct {
@@ -14,6 +15,9 @@ type ExecConfig struct {
 	AttachStdout bool
 	Detach       bool
 	Cmd          []string
+	Memory       int64 // Memory limit of the command's cgroup (in bytes)
+	CpuShares    int64 // CPU shares of the command's cgroup
+	CpuQuota     int64 // CFS quota of the command's cgroup
 }
 
 func ParseExec(cmd *flag.FlagSet, args []string) (*ExecConfig, error) {
@@ -23,6 +27,9 @@ func ParseExec(cmd *flag.FlagSet, args []string) (*ExecConfig, error) {
 		flDetach     = cmd.Bool([]string{"d", "-detach"}, false, "Detached mode: run command in the background")
 		flUser       = cmd.String([]string{"u", "-user"}, "", "Username or UID (format: <name|uid>[:<group|gid>])")
 		flPrivileged = cmd.Bool([]string{"-privileged"}, false, "Give extended privileges to the command")
+		flMemory     = cmd.String([]string{"m", "-memory"}, "", "Memory limit of the command")
+		flCpuShares  = cmd.Int64([]string{"c", "-cpu-shares"}, 0, "CPU shares of the command (relative weight)")
+		flCpuQuota   = cmd.Int64([]string{"-cpu-quota"}, 0, "Limit the CPU CFS quota of the command")
 		execCmd      []string
 		container    string
 	)
@@ -34,6 +41,15 @@ func ParseExec(cmd *flag.FlagSet, args []string) (*ExecConfig, error) {
 	parsedArgs := cmd.Args()
 	execCmd = parsedArgs[1:]
 
+	var memory int64
+	if *flMemory != "" {
+		parsedMemory, err := units.RAMInBytes(*flMemory)
+		if err != nil {
+			return nil, err
+		}
+		memory = parsedMemory
+	}
+
 	execConfig := &ExecConfig{
 		User:       *flUser,
 		Privileged: *flPrivileged,
@@ -41,6 +57,9 @@ func ParseExec(cmd *flag.FlagSet, args []string) (*ExecConfig, error) {
 		Cmd:        execCmd,
 		Container:  container,
 		Detach:     *flDetach,
+		Memory:     memory,
+		CpuShares:  *flCpuShares,
+		CpuQuota:   *flCpuQuota,
 	}
 
 	// If -d is not set, attach to everything by default
@@ -49,6 +49,12 @@ func (n IpcMode) IsHost() bool {
 	return n == "host"
 }
 
+// IsShareable indicates whether other containers can join the container's
+// ipc stack, which they can unless it is private.
+func (n IpcMode) IsShareable() bool {
+	return n == "" || n == "shareable"
+}
+
 func (n IpcMode) IsContainer() bool {
 	parts := strings.SplitN(string(n), ":", 2)
 	return len(parts) > 1 && parts[0] == "container"
@@ -57,7 +63,7 @@ func (n IpcMode) IsContainer() bool {
 func (n IpcMode) Valid() bool {
 	parts := strings.Split(string(n), ":")
 	switch mode := parts[0]; mode {
-	case "", "host":
+	case "", "host", "shareable", "private":
 	case "container":
 		if len(parts) != 2 || parts[1] == "" {
 			return false
@@ -101,29 +107,61 @@ type PidMode string
 
 // IsPrivate indicates whether container use it's private pid stack
 func (n PidMode) IsPrivate() bool {
-	return !(n.IsHost())
+	return !(n.IsHost() || n.IsContainer())
 }
 
 func (n PidMode) IsHost() bool {
 	return n == "host"
 }
 
+func (n PidMode) IsContainer() bool {
+	parts := strings.SplitN(string(n), ":", 2)
+	return len(parts) > 1 && parts[0] == "container"
+}
+
 func (n PidMode) Valid() bool {
 	parts := strings.Split(string(n), ":")
 	switch mode := parts[0]; mode {
 	case "", "host":
+	case "container":
+		if len(parts) != 2 || parts[1] == "" {
+			return false
+		}
 	default:
 		return false
 	}
 	return true
 }
 
+func (n PidMode) Container() string {
+	parts := strings.SplitN(string(n), ":", 2)
+	if len(parts) > 1 {
+		return parts[1]
+	}
+	return ""
+}
+
 type DeviceMapping struct {
 	PathOnHost        string
 	PathInContainer   string
 	CgroupPermissions string
 }
 
+// ThrottleDevice limits the IO of a block device, in bytes or in operations
+// per second.
+type ThrottleDevice struct {
+	Path string
+	Rate uint64
+}
+
+// TmpfsMount is a tmpfs mounted in the container, which is writable even
+// when the root filesystem is read only.
+type TmpfsMount struct {
+	Path string
+	Size int64  // in bytes, the kernel's default of half the memory if 0
+	Mode uint32 // permissions of the root of the tmpfs, 1777 if 0
+}
+
 type RestartPolicy struct {
 	Name              string
 	MaximumRetryCount int
@@ -141,6 +179,12 @@ func (rp *RestartPolicy) IsOnFailure() bool {
 	return rp.Name == "on-failure"
 }
 
+// IsUnlessStopped returns true if the container is restarted like with
+// always, except when the daemon starts if it was stopped by the user.
+func (rp *RestartPolicy) IsUnlessStopped() bool {
+	return rp.Name == "unless-stopped"
+}
+
 type LogConfig struct {
 	Type   string
 	Config map[string]string
@@ -201,13 +245,23 @@ type HostConfig struct {
 	LxcConf         *LxcConfig
 	Memory          int64 // Memory limit (in bytes)
 	MemorySwap      int64 // Total memory usage (memory + swap); set `-1` to disable swap
+	MemoryHigh      int64 // Memory usage above which the container is throttled (in bytes)
 	CpuShares       int64 // CPU shares (relative weight vs. other containers)
 	CpuPeriod       int64
 	CpusetCpus      string // CpusetCpus 0-2, 0,1
 	CpusetMems      string // CpusetMems 0-2, 0,1
 	CpuQuota        int64
-	BlkioWeight     int64 // Block IO weight (relative weight vs. other containers)
-	OomKillDisable  bool  // Whether to disable OOM Killer or not
+	CpuRtPolicy     string           // Real-time scheduling policy, fifo or rr
+	CpuRtPriority   int              // Real-time priority, between 1 and 99
+	CpuRtRuntime    int64            // Real-time budget in microseconds per period
+	CpuRtPeriod     int64            // Real-time period in microseconds
+	BlkioWeight     int64            // Block IO weight (relative weight vs. other containers)
+	BlkioReadBps    []ThrottleDevice // Read limits of block devices in bytes per second
+	BlkioWriteBps   []ThrottleDevice // Write limits of block devices in bytes per second
+	BlkioReadIOps   []ThrottleDevice // Read limits of block devices in operations per second
+	BlkioWriteIOps  []ThrottleDevice // Write limits of block devices in operations per second
+	OomKillDisable  bool             // Whether to disable OOM Killer or not
+	OomScoreAdj     int              // OOM score adjustment of the container's processes, between -1000 and 1000
 	Privileged      bool
 	PortBindings    nat.PortMap
 	Links           []string
@@ -228,7 +282,14 @@ type HostConfig struct {
 	ReadonlyRootfs  bool
 	Ulimits         []*ulimit.Ulimit
 	LogConfig       LogConfig
-	CgroupParent    string // Parent cgroup.
+	CgroupParent    string         // Parent cgroup.
+	DebugStart      bool           // Log the startup of the container's init process
+	NetClassID      uint32         // net_cls class id of the container's traffic
+	NetPrioMap      map[string]int // Priority of the container's traffic per interface
+	ExecDriver      string         // Exec driver running the container, the daemon's if empty
+	Tmpfs           []TmpfsMount   // tmpfs mounted in the container
+	ShmSize         int64          // Size of /dev/shm in bytes, the driver's default if zero
+	Init            bool           // Run an init in the container that forwards signals and reaps zombies
 }
 
 func MergeConfigs(config *Config, hostConfig *HostConfig) *ContainerConfigWrapper {
@@ -20,6 +20,7 @@ var (
 	ErrConflictHostNetworkAndLinks      = fmt.Errorf("Conflicting options: --net=host can't be used with links. This would result in undefined behavior")
 	ErrConflictContainerNetworkAndMac   = fmt.Errorf("Conflicting options: --mac-address and the network mode (--net)")
 	ErrConflictNetworkHosts             = fmt.Errorf("Conflicting options: --add-host and the network mode (--net)")
+	ErrConflictUTSHostname              = fmt.Errorf("Conflicting options: -h and the UTS mode (--uts)")
 )
 
 func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSet, error) {
@@ -49,6 +50,13 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 		flLabelsFile  = opts.NewListOpts(nil)
 		flLoggingOpts = opts.NewListOpts(nil)
 
+		flReadBps   = opts.NewListOpts(nil)
+		flWriteBps  = opts.NewListOpts(nil)
+		flReadIOps  = opts.NewListOpts(nil)
+		flWriteIOps = opts.NewListOpts(nil)
+		flNetPrio   = opts.NewListOpts(nil)
+		flTmpfs     = opts.NewListOpts(nil)
+
 		flNetwork         = cmd.Bool([]string{"#n", "#-networking"}, true, "Enable networking for this container")
 		flPrivileged      = cmd.Bool([]string{"#privileged", "-privileged"}, false, "Give extended privileges to this container")
 		flPidMode         = cmd.String([]string{"-pid"}, "", "PID namespace to use")
@@ -57,11 +65,13 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 		flStdin           = cmd.Bool([]string{"i", "-interactive"}, false, "Keep STDIN open even if not attached")
 		flTty             = cmd.Bool([]string{"t", "-tty"}, false, "Allocate a pseudo-TTY")
 		flOomKillDisable  = cmd.Bool([]string{"-oom-kill-disable"}, false, "Disable OOM Killer")
+		flOomScoreAdj     = cmd.Int([]string{"-oom-score-adj"}, 0, "Tune the host's OOM preferences (-1000 to 1000)")
 		flContainerIDFile = cmd.String([]string{"#cidfile", "-cidfile"}, "", "Write the container ID to the file")
 		flEntrypoint      = cmd.String([]string{"#entrypoint", "-entrypoint"}, "", "Overwrite the default ENTRYPOINT of the image")
 		flHostname        = cmd.String([]string{"h", "-hostname"}, "", "Container host name")
 		flMemoryString    = cmd.String([]string{"m", "-memory"}, "", "Memory limit")
 		flMemorySwap      = cmd.String([]string{"-memory-swap"}, "", "Total memory (memory + swap), '-1' to disable swap")
+		flMemoryHigh      = cmd.String([]string{"-memory-high"}, "", "Memory usage above which the container is throttled")
 		flUser            = cmd.String([]string{"u", "-user"}, "", "Username or UID (format: <name|uid>[:<group|gid>])")
 		flWorkingDir      = cmd.String([]string{"w", "-workdir"}, "", "Working directory inside the container")
 		flCpuShares       = cmd.Int64([]string{"c", "-cpu-shares"}, 0, "CPU shares (relative weight)")
@@ -69,20 +79,35 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 		flCpusetCpus      = cmd.String([]string{"#-cpuset", "-cpuset-cpus"}, "", "CPUs in which to allow execution (0-3, 0,1)")
 		flCpusetMems      = cmd.String([]string{"-cpuset-mems"}, "", "MEMs in which to allow execution (0-3, 0,1)")
 		flCpuQuota        = cmd.Int64([]string{"-cpu-quota"}, 0, "Limit the CPU CFS quota")
+		flCpuRtPolicy     = cmd.String([]string{"-cpu-rt-policy"}, "", "Real-time scheduling policy (fifo, rr)")
+		flCpuRtPriority   = cmd.Int([]string{"-cpu-rt-priority"}, 0, "Real-time scheduling priority, between 1 and 99")
+		flCpuRtRuntime    = cmd.Int64([]string{"-cpu-rt-runtime"}, 0, "Limit the CPU real-time runtime in microseconds")
+		flCpuRtPeriod     = cmd.Int64([]string{"-cpu-rt-period"}, 0, "Limit the CPU real-time period in microseconds")
 		flBlkioWeight     = cmd.Int64([]string{"-blkio-weight"}, 0, "Block IO (relative weight), between 10 and 1000")
 		flNetMode         = cmd.String([]string{"-net"}, "bridge", "Set the Network mode for the container")
 		flMacAddress      = cmd.String([]string{"-mac-address"}, "", "Container MAC address (e.g. 92:d0:c6:0a:29:33)")
 		flIpcMode         = cmd.String([]string{"-ipc"}, "", "IPC namespace to use")
+		flShmSize         = cmd.String([]string{"-shm-size"}, "", "Size of /dev/shm")
 		flRestartPolicy   = cmd.String([]string{"-restart"}, "no", "Restart policy to apply when a container exits")
 		flReadonlyRootfs  = cmd.Bool([]string{"-read-only"}, false, "Mount the container's root filesystem as read only")
 		flLoggingDriver   = cmd.String([]string{"-log-driver"}, "", "Logging driver for container")
 		flCgroupParent    = cmd.String([]string{"-cgroup-parent"}, "", "Optional parent cgroup for the container")
+		flDebugStart      = cmd.Bool([]string{"-debug-start"}, false, "Log the startup of the container's init process to a file")
+		flInit            = cmd.Bool([]string{"-init"}, false, "Run an init in the container that forwards signals and reaps zombies")
+		flNetClassID      = cmd.String([]string{"-net-classid"}, "", "Class id of the container's traffic for tc (e.g. 10:1)")
+		flExecDriver      = cmd.String([]string{"-exec-driver"}, "", "Exec driver to run the container with instead of the daemon's")
 	)
 
 	cmd.Var(&flAttach, []string{"a", "-attach"}, "Attach to STDIN, STDOUT or STDERR")
 	cmd.Var(&flVolumes, []string{"v", "-volume"}, "Bind mount a volume")
 	cmd.Var(&flLinks, []string{"#link", "-link"}, "Add link to another container")
 	cmd.Var(&flDevices, []string{"-device"}, "Add a host device to the container")
+	cmd.Var(&flNetPrio, []string{"-net-prio"}, "Priority of the container's traffic on an interface (e.g. eth0:5)")
+	cmd.Var(&flTmpfs, []string{"-tmpfs"}, "Mount a tmpfs in the container (e.g. /run:size=64m,mode=755)")
+	cmd.Var(&flReadBps, []string{"-device-read-bps"}, "Limit read rate from a device (e.g. /dev/sda:1mb)")
+	cmd.Var(&flWriteBps, []string{"-device-write-bps"}, "Limit write rate to a device (e.g. /dev/sda:1mb)")
+	cmd.Var(&flReadIOps, []string{"-device-read-iops"}, "Limit read operations per second from a device (e.g. /dev/sda:1000)")
+	cmd.Var(&flWriteIOps, []string{"-device-write-iops"}, "Limit write operations per second to a device (e.g. /dev/sda:1000)")
 	cmd.Var(&flLabels, []string{"l", "-label"}, "Set meta data on a container")
 	cmd.Var(&flLabelsFile, []string{"-label-file"}, "Read in a line delimited file of labels")
 	cmd.Var(&flEnv, []string{"e", "-env"}, "Set environment variables")
@@ -179,6 +204,27 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 		}
 	}
 
+	var memoryHigh int64
+	if *flMemoryHigh != "" {
+		parsedMemoryHigh, err := units.RAMInBytes(*flMemoryHigh)
+		if err != nil {
+			return nil, nil, cmd, err
+		}
+		memoryHigh = parsedMemoryHigh
+	}
+
+	var shmSize int64
+	if *flShmSize != "" {
+		parsedShmSize, err := units.RAMInBytes(*flShmSize)
+		if err != nil {
+			return nil, nil, cmd, err
+		}
+		if parsedShmSize <= 0 {
+			return nil, nil, cmd, fmt.Errorf("--shm-size: size must be greater than 0")
+		}
+		shmSize = parsedShmSize
+	}
+
 	var binds []string
 	// add any bind targets to the list of container volumes
 	for bind := range flVolumes.GetMap() {
@@ -186,6 +232,11 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 			if arr[1] == "/" {
 				return nil, nil, cmd, fmt.Errorf("Invalid bind mount: destination can't be '/'")
 			}
+			if len(arr) > 2 {
+				if _, _, err := ParseBindMode(arr[2]); err != nil {
+					return nil, nil, cmd, err
+				}
+			}
 			// after creating the bind mount we want to delete it from the flVolumes values because
 			// we do not want bind mounts being committed to image configs
 			binds = append(binds, bind)
@@ -260,6 +311,38 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 		deviceMappings = append(deviceMappings, deviceMapping)
 	}
 
+	// parse block device limits
+	var throttle [4][]ThrottleDevice
+	for i, fl := range []*opts.ListOpts{&flReadBps, &flWriteBps, &flReadIOps, &flWriteIOps} {
+		for _, val := range fl.GetAll() {
+			device, err := ParseThrottleDevice(val, i < 2)
+			if err != nil {
+				return nil, nil, cmd, err
+			}
+			throttle[i] = append(throttle[i], device)
+		}
+	}
+
+	var tmpfs []TmpfsMount
+	for _, val := range flTmpfs.GetAll() {
+		mount, err := ParseTmpfs(val)
+		if err != nil {
+			return nil, nil, cmd, err
+		}
+		tmpfs = append(tmpfs, mount)
+	}
+
+	var netClassID uint32
+	if *flNetClassID != "" {
+		if netClassID, err = ParseNetClassID(*flNetClassID); err != nil {
+			return nil, nil, cmd, err
+		}
+	}
+	netPrioMap, err := parseNetPrio(flNetPrio.GetAll())
+	if err != nil {
+		return nil, nil, cmd, err
+	}
+
 	// collect all the environment variables for the container
 	envVariables, err := readKVStrings(flEnvFile.GetAll(), flEnv.GetAll())
 	if err != nil {
@@ -286,6 +369,9 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 	if !utsMode.Valid() {
 		return nil, nil, cmd, fmt.Errorf("--uts: invalid UTS mode")
 	}
+	if utsMode.IsHost() && *flHostname != "" {
+		return nil, nil, cmd, ErrConflictUTSHostname
+	}
 
 	restartPolicy, err := ParseRestartPolicy(*flRestartPolicy)
 	if err != nil {
@@ -325,13 +411,23 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 		LxcConf:         lxcConf,
 		Memory:          flMemory,
 		MemorySwap:      MemorySwap,
+		MemoryHigh:      memoryHigh,
 		CpuShares:       *flCpuShares,
 		CpuPeriod:       *flCpuPeriod,
 		CpusetCpus:      *flCpusetCpus,
 		CpusetMems:      *flCpusetMems,
 		CpuQuota:        *flCpuQuota,
+		CpuRtPolicy:     *flCpuRtPolicy,
+		CpuRtPriority:   *flCpuRtPriority,
+		CpuRtRuntime:    *flCpuRtRuntime,
+		CpuRtPeriod:     *flCpuRtPeriod,
 		BlkioWeight:     *flBlkioWeight,
+		BlkioReadBps:    throttle[0],
+		BlkioWriteBps:   throttle[1],
+		BlkioReadIOps:   throttle[2],
+		BlkioWriteIOps:  throttle[3],
 		OomKillDisable:  *flOomKillDisable,
+		OomScoreAdj:     *flOomScoreAdj,
 		Privileged:      *flPrivileged,
 		PortBindings:    portBindings,
 		Links:           flLinks.GetAll(),
@@ -342,6 +438,7 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 		VolumesFrom:     flVolumesFrom.GetAll(),
 		NetworkMode:     netMode,
 		IpcMode:         ipcMode,
+		ShmSize:         shmSize,
 		PidMode:         pidMode,
 		UTSMode:         utsMode,
 		Devices:         deviceMappings,
@@ -353,6 +450,12 @@ func Parse(cmd *flag.FlagSet, args []string) (*Config, *HostConfig, *flag.FlagSe
 		Ulimits:         flUlimits.GetList(),
 		LogConfig:       LogConfig{Type: *flLoggingDriver, Config: loggingOpts},
 		CgroupParent:    *flCgroupParent,
+		DebugStart:      *flDebugStart,
+		Init:            *flInit,
+		NetClassID:      netClassID,
+		NetPrioMap:      netPrioMap,
+		ExecDriver:      *flExecDriver,
+		Tmpfs:           tmpfs,
 	}
 
 	// When allocating stdin in attached mode, close stdin at client disconnect
@@ -421,6 +524,10 @@ func ParseRestartPolicy(policy string) (RestartPolicy, error) {
 		if len(parts) == 2 {
 			return p, fmt.Errorf("maximum restart count not valid with restart policy of \"always\"")
 		}
+	case "unless-stopped":
+		if len(parts) == 2 {
+			return p, fmt.Errorf("maximum restart count not valid with restart policy of \"unless-stopped\"")
+		}
 	case "no":
 		// do nothing
 	case "on-failure":
@@ -513,3 +620,136 @@ func ParseDevice(device string) (DeviceMapping, error) {
 	}
 	return deviceMapping, nil
 }
+
+// ParseThrottleDevice parses a block device limit given as path:rate.  Rates
+// in bytes accept units (e.g. 10mb), rates in operations must be integers.
+func ParseThrottleDevice(val string, bytes bool) (ThrottleDevice, error) {
+	i := strings.LastIndex(val, ":")
+	if i <= 0 || !strings.HasPrefix(val, "/") {
+		return ThrottleDevice{}, fmt.Errorf("Invalid device limit %s, expected /path/to/device:rate", val)
+	}
+	var (
+		rate uint64
+		err  error
+	)
+	if bytes {
+		var n int64
+		if n, err = units.RAMInBytes(val[i+1:]); err == nil && n < 0 {
+			err = fmt.Errorf("negative rate")
+		}
+		rate = uint64(n)
+	} else {
+		rate, err = strconv.ParseUint(val[i+1:], 10, 64)
+	}
+	if err != nil {
+		return ThrottleDevice{}, fmt.Errorf("Invalid rate in device limit %s: %v", val, err)
+	}
+	return ThrottleDevice{Path: val[:i], Rate: rate}, nil
+}
+
+// ParseTmpfs parses a tmpfs mount given as a path optionally followed by a
+// colon and comma separated size and mode options, e.g. /run:size=64m,mode=755.
+func ParseTmpfs(val string) (TmpfsMount, error) {
+	parts := strings.SplitN(val, ":", 2)
+	mount := TmpfsMount{Path: parts[0]}
+	if !strings.HasPrefix(mount.Path, "/") {
+		return TmpfsMount{}, fmt.Errorf("Invalid tmpfs %s, the path must be absolute", val)
+	}
+	if len(parts) == 1 {
+		return mount, nil
+	}
+	for _, opt := range strings.Split(parts[1], ",") {
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) != 2 {
+			return TmpfsMount{}, fmt.Errorf("Invalid tmpfs option %s, expected size=<size> or mode=<mode>", opt)
+		}
+		switch kv[0] {
+		case "size":
+			size, err := units.RAMInBytes(kv[1])
+			if err != nil || size <= 0 {
+				return TmpfsMount{}, fmt.Errorf("Invalid size in tmpfs %s", val)
+			}
+			mount.Size = size
+		case "mode":
+			mode, err := strconv.ParseUint(kv[1], 8, 32)
+			if err != nil || mode&^07777 != 0 {
+				return TmpfsMount{}, fmt.Errorf("Invalid mode in tmpfs %s", val)
+			}
+			mount.Mode = uint32(mode)
+		default:
+			return TmpfsMount{}, fmt.Errorf("Invalid tmpfs option %s, expected size=<size> or mode=<mode>", opt)
+		}
+	}
+	return mount, nil
+}
+
+// bindPropagations are the propagations a bind mount can be given in its mode.
+var bindPropagations = map[string]bool{
+	"shared":   true,
+	"rshared":  true,
+	"slave":    true,
+	"rslave":   true,
+	"private":  true,
+	"rprivate": true,
+}
+
+// ParseBindMode parses the mode of a bind mount given to -v, a comma
+// separated list of rw or ro and of a propagation, such as ro,slave.  A bind
+// mount is writable unless ro is given.
+func ParseBindMode(mode string) (bool, string, error) {
+	writable, propagation := true, ""
+	for _, opt := range strings.Split(mode, ",") {
+		switch {
+		case opt == "rw":
+			writable = true
+		case opt == "ro":
+			writable = false
+		case bindPropagations[opt] && propagation == "":
+			propagation = opt
+		default:
+			return false, "", fmt.Errorf("Invalid mode for bind mount: %s", mode)
+		}
+	}
+	return writable, propagation, nil
+}
+
+// ParseNetClassID parses a net_cls class id given either as a number or as a
+// tc handle major:minor in hexadecimal.
+func ParseNetClassID(val string) (uint32, error) {
+	if parts := strings.SplitN(val, ":", 2); len(parts) == 2 {
+		major, err := strconv.ParseUint(parts[0], 16, 16)
+		if err != nil {
+			return 0, fmt.Errorf("Invalid class id %s: %v", val, err)
+		}
+		minor, err := strconv.ParseUint(parts[1], 16, 16)
+		if err != nil {
+			return 0, fmt.Errorf("Invalid class id %s: %v", val, err)
+		}
+		return uint32(major<<16 | minor), nil
+	}
+	id, err := strconv.ParseUint(val, 0, 32)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid class id %s: %v", val, err)
+	}
+	return uint32(id), nil
+}
+
+// parseNetPrio parses the network priorities given as interface:priority.
+func parseNetPrio(vals []string) (map[string]int, error) {
+	if len(vals) == 0 {
+		return nil, nil
+	}
+	prios := make(map[string]int)
+	for _, val := range vals {
+		i := strings.LastIndex(val, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("Invalid network priority %s, expected interface:priority", val)
+		}
+		prio, err := strconv.ParseUint(val[i+1:], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid network priority %s: %v", val, err)
+		}
+		prios[val[:i]] = int(prio)
+	}
+	return prios, nil
+}
@@ -63,3 +63,166 @@ func TestConflictContainerNetworkAndLinks(t *testing.T) {
 		t.Fatalf("Expected error ErrConflictContainerNetworkAndLinks, got: %s", err)
 	}
 }
+
+func TestParseThrottleDevice(t *testing.T) {
+	_, hostConfig, _, err := parseRun([]string{"--device-read-bps=/dev/sda:1mb", "--device-write-iops=/dev/sdb:100", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hostConfig.BlkioReadBps) != 1 || hostConfig.BlkioReadBps[0] != (ThrottleDevice{"/dev/sda", 1024 * 1024}) {
+		t.Fatalf("unexpected read bps limits %v", hostConfig.BlkioReadBps)
+	}
+	if len(hostConfig.BlkioWriteIOps) != 1 || hostConfig.BlkioWriteIOps[0] != (ThrottleDevice{"/dev/sdb", 100}) {
+		t.Fatalf("unexpected write iops limits %v", hostConfig.BlkioWriteIOps)
+	}
+
+	for _, val := range []string{"/dev/sda", "sda:1mb", "/dev/sda:fast", "/dev/sda:-1"} {
+		if _, err := ParseThrottleDevice(val, true); err == nil {
+			t.Fatalf("expected an error for %s", val)
+		}
+	}
+	if _, err := ParseThrottleDevice("/dev/sda:1mb", false); err == nil {
+		t.Fatal("expected an error for a rate in bytes given as operations")
+	}
+}
+
+func TestParseNetClass(t *testing.T) {
+	_, hostConfig, _, err := parseRun([]string{"--net-classid=10:1", "--net-prio=eth0:5", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hostConfig.NetClassID != 0x100001 {
+		t.Fatalf("expected class id 0x100001, got %#x", hostConfig.NetClassID)
+	}
+	if len(hostConfig.NetPrioMap) != 1 || hostConfig.NetPrioMap["eth0"] != 5 {
+		t.Fatalf("unexpected network priorities %v", hostConfig.NetPrioMap)
+	}
+	if id, err := ParseNetClassID("0x100001"); err != nil || id != 0x100001 {
+		t.Fatalf("expected class id 0x100001, got %#x (%v)", id, err)
+	}
+
+	for _, args := range [][]string{{"--net-classid=10:x"}, {"--net-prio=eth0"}, {"--net-prio=eth0:-1"}} {
+		if _, _, _, err := parseRun(append(args, "img", "cmd")); err == nil {
+			t.Fatalf("expected an error for %v", args)
+		}
+	}
+}
+
+func TestParseTmpfs(t *testing.T) {
+	_, hostConfig, _, err := parseRun([]string{"--read-only", "--tmpfs=/run:size=64m,mode=755", "--tmpfs=/tmp", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []TmpfsMount{{Path: "/run", Size: 64 * 1024 * 1024, Mode: 0755}, {Path: "/tmp"}}
+	if !hostConfig.ReadonlyRootfs || len(hostConfig.Tmpfs) != 2 || hostConfig.Tmpfs[0] != expected[0] || hostConfig.Tmpfs[1] != expected[1] {
+		t.Fatalf("expected tmpfs %v on a read only rootfs, got %v", expected, hostConfig.Tmpfs)
+	}
+
+	for _, val := range []string{"run", "/run:size=big", "/run:mode=999", "/run:mode=17777", "/run:uid=0", "/run:size"} {
+		if _, err := ParseTmpfs(val); err == nil {
+			t.Fatalf("expected an error for %s", val)
+		}
+	}
+}
+
+func TestParseBindMode(t *testing.T) {
+	_, hostConfig, _, err := parseRun([]string{"-v", "/var/lib/plugin:/plugin:shared", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hostConfig.Binds) != 1 || hostConfig.Binds[0] != "/var/lib/plugin:/plugin:shared" {
+		t.Fatalf("expected a shared bind mount of /plugin, got %v", hostConfig.Binds)
+	}
+
+	for _, tc := range []struct {
+		mode        string
+		writable    bool
+		propagation string
+	}{
+		{"rw", true, ""},
+		{"ro", false, ""},
+		{"slave", true, "slave"},
+		{"ro,private", false, "private"},
+	} {
+		writable, propagation, err := ParseBindMode(tc.mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if writable != tc.writable || propagation != tc.propagation {
+			t.Fatalf("expected %s to be writable %v with propagation %q, got %v and %q", tc.mode, tc.writable, tc.propagation, writable, propagation)
+		}
+	}
+	for _, mode := range []string{"", "unbindable", "shared,slave", "ro;shared"} {
+		if _, _, err := ParseBindMode(mode); err == nil {
+			t.Fatalf("expected an error for %q", mode)
+		}
+	}
+	if _, _, _, err := parseRun([]string{"-v", "/data:/data:shared,slave", "img", "cmd"}); err == nil {
+		t.Fatal("expected an error for two propagations")
+	}
+}
+
+func TestParseIpcShm(t *testing.T) {
+	_, hostConfig, _, err := parseRun([]string{"--ipc=shareable", "--shm-size=128m", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hostConfig.IpcMode.IsShareable() || hostConfig.ShmSize != 128*1024*1024 {
+		t.Fatalf("expected a shareable IPC with a 128m /dev/shm, got %q and %d", hostConfig.IpcMode, hostConfig.ShmSize)
+	}
+	_, hostConfig, _, err = parseRun([]string{"--ipc=private", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hostConfig.IpcMode.IsPrivate() || hostConfig.IpcMode.IsShareable() {
+		t.Fatalf("expected a private IPC, got %q", hostConfig.IpcMode)
+	}
+
+	for _, args := range [][]string{{"--ipc=shared"}, {"--shm-size=0"}, {"--shm-size=big"}} {
+		if _, _, _, err := parseRun(append(args, "img", "cmd")); err == nil {
+			t.Fatalf("expected an error for %v", args)
+		}
+	}
+}
+
+func TestParsePidMode(t *testing.T) {
+	_, hostConfig, _, err := parseRun([]string{"--pid=container:db", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hostConfig.PidMode.IsContainer() || hostConfig.PidMode.IsPrivate() || hostConfig.PidMode.Container() != "db" {
+		t.Fatalf("expected to join the PID namespace of db, got %q", hostConfig.PidMode)
+	}
+
+	for _, mode := range []string{"container", "container:", "shareable"} {
+		if _, _, _, err := parseRun([]string{"--pid=" + mode, "img", "cmd"}); err == nil {
+			t.Fatalf("expected an error for --pid=%s", mode)
+		}
+	}
+}
+
+func TestConflictUTSHostname(t *testing.T) {
+	if _, _, _, err := parseRun([]string{"--uts=host", "-h=name", "img", "cmd"}); err != ErrConflictUTSHostname {
+		t.Fatalf("Expected error ErrConflictUTSHostname, got: %s", err)
+	}
+	_, hostConfig, _, err := parseRun([]string{"--uts=host", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hostConfig.UTSMode.IsHost() {
+		t.Fatalf("expected the host UTS mode, got %q", hostConfig.UTSMode)
+	}
+}
+
+func TestParseRestartPolicyUnlessStopped(t *testing.T) {
+	p, err := ParseRestartPolicy("unless-stopped")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.IsUnlessStopped() || p.IsAlways() {
+		t.Fatalf("expected the unless-stopped policy, got %q", p.Name)
+	}
+	if _, err := ParseRestartPolicy("unless-stopped:3"); err == nil {
+		t.Fatal("expected an error for a maximum restart count with unless-stopped")
+	}
+}